@@ -6,6 +6,7 @@ require (
 	github.com/openai/openai-go v1.12.0
 	github.com/rs/zerolog v1.33.0
 	github.com/spf13/cobra v1.8.1
+	golang.org/x/sys v0.29.0
 )
 
 require (
@@ -17,5 +18,4 @@ require (
 	github.com/tidwall/match v1.2.0 // indirect
 	github.com/tidwall/pretty v1.2.1 // indirect
 	github.com/tidwall/sjson v1.2.5 // indirect
-	golang.org/x/sys v0.29.0 // indirect
 )
@@ -4,14 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/cache"
 	"github.com/arykalin/whisper-cli/internal/config"
 	"github.com/arykalin/whisper-cli/internal/domain"
 	"github.com/arykalin/whisper-cli/internal/output"
@@ -29,6 +33,11 @@ type Application struct {
 	Registry provider.Registry
 	Logger   zerolog.Logger
 	Env      config.EnvSource
+	Stdin    io.Reader
+	// ProvidersErr is why the OpenAI-compatible provider definitions
+	// could not be loaded. It is reported when the requested provider is
+	// not in Registry, since it was most likely declared there.
+	ProvidersErr error
 }
 
 func NewDefault() *Application {
@@ -39,44 +48,72 @@ func NewDefault() *Application {
 		Runner: execx.OS{},
 	}
 
+	clients := []provider.Client{
+		openaiadapter.New(strings.TrimSpace(os.Getenv("OPENAI_API_KEY")), filesystem, logger),
+		groqadapter.New(strings.TrimSpace(os.Getenv("GROQ_API_KEY")), filesystem, logger),
+		provider.NewBlockedClient(domain.ProviderOpenRouter, provider.ErrOpenRouterPlanned),
+	}
+	compatible, providersErr := compatibleClients(filesystem, config.OSEnv{}, logger)
+	if providersErr != nil {
+		logger.Error().Err(providersErr).Msg("failed to load OpenAI-compatible provider definitions")
+	}
+	clients = append(clients, compatible...)
+
 	return &Application{
-		FS:    filesystem,
-		Audio: audioService,
-		Registry: provider.NewRegistry(
-			openaiadapter.New(strings.TrimSpace(os.Getenv("OPENAI_API_KEY")), filesystem, logger),
-			groqadapter.New(strings.TrimSpace(os.Getenv("GROQ_API_KEY")), filesystem, logger),
-			provider.NewBlockedClient(domain.ProviderOpenRouter, provider.ErrOpenRouterPlanned),
-		),
-		Logger: logger,
-		Env:    config.OSEnv{},
+		FS:           filesystem,
+		Audio:        audioService,
+		Registry:     provider.NewRegistry(clients...),
+		Logger:       logger,
+		Env:          config.OSEnv{},
+		Stdin:        os.Stdin,
+		ProvidersErr: providersErr,
 	}
 }
 
 func (a *Application) Run(ctx context.Context, cfg config.Config) error {
-	if err := a.Audio.EnsureBinaries(); err != nil {
+	client, cfg, err := a.prepareRun(cfg)
+	if err != nil {
 		return err
 	}
 
-	client, err := a.Registry.Provider(cfg.Provider)
+	outputRoot, err := a.FS.Abs(filepath.Clean(cfg.OutputDir))
 	if err != nil {
-		return err
+		return fmt.Errorf("resolve output dir: %w", err)
 	}
-	if err := client.Preflight(); err != nil {
-		return err
+	pool := newWorkerPool(cfg.Concurrency, cfg.FFmpegJobs)
+	started := time.Now()
+
+	if cfg.Input == stdinInput {
+		fileOutputDir := filepath.Join(outputRoot, stdinOutputName)
+		source, err := a.spoolStdin(filepath.Join(fileOutputDir, audio.WorkDirName))
+		if err != nil {
+			return err
+		}
+		entry := a.processTracked(ctx, client, pool, nil, cfg, source, fileOutputDir)
+		return a.finishRun(cfg, outputRoot, started, []fileReport{entry})
 	}
-	cfg, err = normalizeConfigAgainstCapabilities(client, cfg, a.Logger)
+
+	manifest, err := loadManifest(a.FS, outputRoot)
 	if err != nil {
 		return err
 	}
 
+	if cfg.InputList != "" {
+		files, err := a.readInputList(cfg.InputList)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return errors.New("input list does not contain any files")
+		}
+		entries := a.processFiles(ctx, client, pool, manifest, cfg, files, mirrorOutputDirs(commonDir(files), outputRoot, files))
+		return a.finishRun(cfg, outputRoot, started, entries)
+	}
+
 	inputPath, err := a.FS.Abs(filepath.Clean(cfg.Input))
 	if err != nil {
 		return fmt.Errorf("resolve input path: %w", err)
 	}
-	outputRoot, err := a.FS.Abs(filepath.Clean(cfg.OutputDir))
-	if err != nil {
-		return fmt.Errorf("resolve output dir: %w", err)
-	}
 
 	info, err := a.FS.Stat(inputPath)
 	if err != nil {
@@ -84,27 +121,162 @@ func (a *Application) Run(ctx context.Context, cfg config.Config) error {
 	}
 
 	if info.IsDir() {
-		files, err := a.Audio.CollectMediaFiles(inputPath)
+		scan := cfg.Scan
+		scan.OutputDir = outputRoot
+		files, skipped, err := a.Audio.CollectMediaFiles(ctx, inputPath, scan)
 		if err != nil {
 			return fmt.Errorf("scan input directory: %w", err)
 		}
+		for _, item := range skipped {
+			a.Logger.Warn().
+				Str("file", item.Path).
+				Str("reason", item.Reason).
+				Msg("skipped file without decodable audio")
+		}
 		if len(files) == 0 {
 			return errors.New("input directory does not contain supported media files")
 		}
+		entries := a.processFiles(ctx, client, pool, manifest, cfg, files, mirrorOutputDirs(inputPath, outputRoot, files))
+		return a.finishRun(cfg, outputRoot, started, entries)
+	}
 
-		var firstErr error
-		for _, file := range files {
-			if err := a.processFile(ctx, client, cfg, file, outputRoot); err != nil {
-				a.Logger.Error().Err(err).Str("file", file).Msg("failed to transcribe file")
-				if firstErr == nil {
-					firstErr = err
-				}
+	baseName := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	entry := a.processTracked(ctx, client, pool, manifest, cfg, inputPath, filepath.Join(outputRoot, baseName))
+	return a.finishRun(cfg, outputRoot, started, []fileReport{entry})
+}
+
+// prepareRun checks the binaries and the provider, and resolves the model
+// and the settings that depend on its capabilities.
+func (a *Application) prepareRun(cfg config.Config) (provider.Client, config.Config, error) {
+	if err := a.Audio.EnsureBinaries(); err != nil {
+		return nil, config.Config{}, &ClassError{Class: ErrorClassMissingFFmpeg, Err: err}
+	}
+
+	client, err := a.Registry.Provider(cfg.Provider)
+	if err != nil {
+		if a.ProvidersErr != nil {
+			err = fmt.Errorf("%w; OpenAI-compatible provider definitions were not loaded: %w", err, a.ProvidersErr)
+		}
+		return nil, config.Config{}, &ClassError{Class: ErrorClassConfig, Err: err}
+	}
+	if err := client.Preflight(); err != nil {
+		return nil, config.Config{}, &ClassError{Class: ErrorClassConfig, Err: err}
+	}
+	if cfg.Model == "" {
+		models := client.SupportedModels()
+		if len(models) != 1 {
+			return nil, config.Config{}, &ClassError{
+				Class: ErrorClassConfig,
+				Err:   fmt.Errorf("provider %s requires --model; supported models: %s", cfg.Provider, strings.Join(models, ", ")),
+			}
+		}
+		cfg.Model = models[0]
+	}
+	cfg, err = normalizeConfigAgainstCapabilities(client, cfg, a.Logger)
+	if err != nil {
+		return nil, config.Config{}, &ClassError{Class: ErrorClassConfig, Err: err}
+	}
+	return client, cfg, nil
+}
+
+// processFiles transcribes a batch concurrently and reports every file in
+// input order. At most as many files are in flight as the pool has slots,
+// so preprocessing of the next files overlaps with uploads of the current
+// ones without preparing the whole batch up front.
+func (a *Application) processFiles(
+	ctx context.Context,
+	client provider.Client,
+	pool *workerPool,
+	manifest *runManifest,
+	cfg config.Config,
+	files []string,
+	outputDirs map[string]string,
+) []fileReport {
+	inFlight := newSemaphore(cap(pool.chunks) + cap(pool.ffmpeg))
+	entries := make([]fileReport, len(files))
+
+	var wg sync.WaitGroup
+	for idx, file := range files {
+		if err := inFlight.acquire(ctx); err != nil {
+			for rest := idx; rest < len(files); rest++ {
+				entries[rest] = newFileReport(cfg, files[rest], outputDirs[files[rest]])
+				entries[rest].fail(err)
 			}
+			break
 		}
-		return firstErr
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer inFlight.release()
+			entries[idx] = a.processTracked(ctx, client, pool, manifest, cfg, file, outputDirs[file])
+		}()
 	}
+	wg.Wait()
+	return entries
+}
+
+// processTracked transcribes a file, records it in the manifest and
+// reports how it went. With --incremental, files the manifest shows as up
+// to date are skipped. A nil manifest disables both.
+func (a *Application) processTracked(
+	ctx context.Context,
+	client provider.Client,
+	pool *workerPool,
+	manifest *runManifest,
+	cfg config.Config,
+	file string,
+	outputDir string,
+) fileReport {
+	entry := newFileReport(cfg, file, outputDir)
+	if manifest != nil && cfg.Incremental {
+		current, err := manifest.upToDate(file, cfg, outputDir)
+		if err != nil {
+			a.Logger.Warn().Err(err).Str("file", file).Msg("cannot check manifest; transcribing file again")
+		}
+		if current {
+			a.Logger.Info().
+				Str("input", file).
+				Str("output_dir", outputDir).
+				Msg("skipping file unchanged since the last run")
+			entry.Status = fileSkipped
+			return entry
+		}
+	}
+
+	started := time.Now()
+	var retries atomic.Int64
+	result, err := a.processFile(provider.WithRetryCounter(ctx, &retries), client, pool, cfg, file, outputDir)
+	entry.ElapsedSeconds = time.Since(started).Seconds()
+	entry.Retries = retries.Load()
+	entry.DurationSeconds = result.duration
+	entry.Chunks = result.chunks
+	entry.FailedChunks = result.failedChunks
+	if err != nil {
+		a.Logger.Error().Err(err).Str("file", file).Msg("failed to transcribe file")
+		entry.fail(err)
+		return entry
+	}
+	if result.failedChunks > 0 {
+		// Keep partial transcripts out of the manifest so --incremental
+		// retries them; checkpoints limit the retry to the failed chunks.
+		entry.Status = filePartial
+		return entry
+	}
+	entry.Status = fileOK
+	if manifest != nil {
+		if err := manifest.record(file, cfg, outputDir); err != nil {
+			a.Logger.Warn().Err(err).Str("file", file).Msg("failed to record file in manifest")
+		}
+	}
+	return entry
+}
 
-	return a.processFile(ctx, client, cfg, inputPath, outputRoot)
+// AudioStreams lists the audio streams of a media file.
+func (a *Application) AudioStreams(ctx context.Context, input string) ([]audio.AudioStream, error) {
+	if err := a.Audio.EnsureBinaries(); err != nil {
+		return nil, err
+	}
+	return a.Audio.ListAudioStreams(ctx, input)
 }
 
 func normalizeConfigAgainstCapabilities(client provider.Client, cfg config.Config, logger zerolog.Logger) (config.Config, error) {
@@ -116,6 +288,14 @@ func normalizeConfigAgainstCapabilities(client provider.Client, cfg config.Confi
 	if cfg.Prompt != "" && !caps.SupportsPrompt {
 		return config.Config{}, fmt.Errorf("model %s does not support prompt", cfg.Model)
 	}
+	if cfg.Task == domain.TaskTranslate {
+		if !caps.SupportsTranslation {
+			return config.Config{}, fmt.Errorf("model %s does not support translation", cfg.Model)
+		}
+		if cfg.Outputs.Enabled(domain.ArtifactDiarized) || cfg.Outputs.Enabled(domain.ArtifactWords) {
+			return config.Config{}, errors.New("diarized and words artifacts are not available for translation")
+		}
+	}
 	if cfg.Outputs.Enabled(domain.ArtifactTimestamps) && !caps.SupportsSegmentTimestamps {
 		delete(cfg.Outputs, domain.ArtifactTimestamps)
 		logger.Warn().
@@ -129,23 +309,54 @@ func normalizeConfigAgainstCapabilities(client provider.Client, cfg config.Confi
 	if cfg.Outputs.Enabled(domain.ArtifactVTT) && !caps.SupportsVTT {
 		return config.Config{}, fmt.Errorf("model %s does not support vtt artifacts", cfg.Model)
 	}
-	if cfg.Outputs.Enabled(domain.ArtifactDiarized) && !caps.SupportsDiarization {
+	if len(cfg.ChannelLabels) > 0 && !caps.SupportsSegmentTimestamps {
+		return config.Config{}, fmt.Errorf("model %s does not return segment timestamps needed to merge channels", cfg.Model)
+	}
+	// Per-channel transcription labels speakers by channel, so it produces
+	// diarized output without a diarization model.
+	if cfg.Outputs.Enabled(domain.ArtifactDiarized) && !caps.SupportsDiarization && len(cfg.ChannelLabels) == 0 {
 		return config.Config{}, fmt.Errorf("model %s does not support diarization", cfg.Model)
 	}
+	if cfg.Outputs.Enabled(domain.ArtifactWords) && !caps.SupportsWordTimestamps {
+		return config.Config{}, fmt.Errorf("model %s does not support word timestamps", cfg.Model)
+	}
+
+	if cfg.AudioProfile == "" {
+		cfg.AudioProfile = audio.ProfileName(caps.AudioProfile)
+	}
+	if cfg.AudioProfile == "" {
+		cfg.AudioProfile = audio.ProfileDefault
+	}
+	profile, ok := audio.LookupProfile(cfg.AudioProfile)
+	if !ok {
+		return config.Config{}, fmt.Errorf("unsupported audio profile %q", cfg.AudioProfile)
+	}
+	format := strings.TrimPrefix(profile.Format, ".")
+	if len(caps.InputFormats) > 0 && !slices.Contains(caps.InputFormats, format) {
+		return config.Config{}, fmt.Errorf("model %s does not accept %s uploads required by audio profile %s; accepted formats: %s", cfg.Model, format, profile.Name, strings.Join(caps.InputFormats, ", "))
+	}
 
 	return cfg, nil
 }
 
+// fileResult describes how a processed file turned out. transcribeChunks
+// adds to it as sources are transcribed.
+type fileResult struct {
+	chunks       int
+	failedChunks int
+	// duration is the transcribed audio in seconds of the input.
+	duration float64
+}
+
 func (a *Application) processFile(
 	ctx context.Context,
 	client provider.Client,
+	pool *workerPool,
 	cfg config.Config,
 	inputPath string,
-	outputRoot string,
-) error {
-	baseName := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
-	fileOutputDir := filepath.Join(outputRoot, baseName)
-	fileWorkDir := filepath.Join(fileOutputDir, "_work")
+	fileOutputDir string,
+) (fileResult, error) {
+	fileWorkDir := filepath.Join(fileOutputDir, audio.WorkDirName)
 
 	a.Logger.Info().
 		Str("input", inputPath).
@@ -154,53 +365,164 @@ func (a *Application) processFile(
 		Msg("processing input file")
 
 	if err := a.FS.MkdirAll(fileOutputDir, 0o755); err != nil {
-		return fmt.Errorf("create output directory: %w", err)
+		return fileResult{}, fmt.Errorf("create output directory: %w", err)
 	}
 
-	prepared, err := a.Audio.PrepareInput(ctx, inputPath, fileWorkDir)
+	profile, _ := audio.LookupProfile(cfg.AudioProfile)
+	if err := pool.ffmpeg.acquire(ctx); err != nil {
+		return fileResult{}, err
+	}
+	prepared, err := a.Audio.PrepareInput(ctx, inputPath, fileWorkDir, audio.InputOptions{
+		Profile:       profile,
+		RemoveSilence: float64(cfg.RemoveSilence),
+		Speed:         cfg.Speed,
+		Filter:        audio.FilterChain(cfg.AudioFilters, cfg.AudioFilterRaw),
+		AudioTrack:    cfg.AudioTrack,
+		Channels:      len(cfg.ChannelLabels),
+		Ranges:        cfg.Ranges,
+	})
+	pool.ffmpeg.release()
 	if err != nil {
-		return err
+		return fileResult{}, err
 	}
 	logEvent := a.Logger.Info().
 		Str("input", prepared.OriginalPath).
 		Str("chunk_source", prepared.ChunkSourcePath).
-		Str("work_dir", fileWorkDir)
+		Str("work_dir", fileWorkDir).
+		Str("audio_profile", string(profile.Name))
+	if len(prepared.TimeMap) > 0 {
+		logEvent = logEvent.Int("kept_spans", len(prepared.TimeMap))
+	}
+	if prepared.Speed > 0 {
+		logEvent = logEvent.Float64("speed", prepared.Speed)
+	}
+	if prepared.Filter != "" {
+		logEvent = logEvent.Str("audio_filter", prepared.Filter)
+	}
+	if prepared.AudioStream != nil {
+		logEvent = logEvent.
+			Int("audio_track", prepared.AudioStream.Track).
+			Str("audio_language", prepared.AudioStream.Language)
+	}
 	if prepared.Converted {
-		logEvent.Msg("converted input to m4a for preprocessing")
+		logEvent.Msg("re-encoded input for preprocessing")
 	} else {
-		logEvent.Msg("input already m4a; skipping conversion")
+		logEvent.Msg("input already matches audio profile; skipping conversion")
 	}
 
-	a.Logger.Info().
-		Str("input", prepared.OriginalPath).
-		Str("chunk_source", prepared.ChunkSourcePath).
-		Str("work_dir", fileWorkDir).
-		Int("chunk_seconds", cfg.ChunkSeconds).
-		Msg("preparing chunks")
-
-	chunks, err := a.Audio.PrepareChunks(ctx, prepared.ChunkSourcePath, fileWorkDir, cfg.ChunkSeconds)
-	if err != nil {
-		return err
+	caps, _ := client.Capabilities(cfg.Model)
+	var (
+		result       fileResult
+		transcript   domain.Transcript
+		rawArtifacts [][]byte
+	)
+	if len(prepared.ChannelPaths) > 0 {
+		transcript, rawArtifacts, err = a.transcribeChannels(ctx, client, pool, cfg, caps, prepared, fileWorkDir, &result)
+	} else {
+		transcript, rawArtifacts, err = a.transcribeSource(ctx, client, pool, cfg, caps, prepared, prepared.ChunkSourcePath, fileWorkDir, &result)
 	}
-	a.Logger.Info().
-		Str("input", prepared.OriginalPath).
-		Str("work_dir", fileWorkDir).
-		Int("chunks", len(chunks)).
-		Msg("prepared chunks")
-
-	transcript, rawArtifacts, err := a.transcribeChunks(ctx, client, cfg, prepared.OriginalPath, chunks)
 	if err != nil {
-		return err
+		return result, err
 	}
+	result.failedChunks = len(transcript.FailedChunks)
 
 	if err := output.WriteArtifacts(a.FS, fileOutputDir, transcript, cfg.Outputs, rawArtifacts); err != nil {
-		return err
+		return result, err
+	}
+	if cfg.Outputs.Enabled(domain.ArtifactWords) {
+		if err := output.WriteWords(a.FS, fileOutputDir, transcript.Words); err != nil {
+			return result, err
+		}
+	}
+	if len(transcript.FailedChunks) > 0 {
+		a.Logger.Warn().
+			Str("input", prepared.OriginalPath).
+			Str("output_dir", fileOutputDir).
+			Int("failed_chunks", len(transcript.FailedChunks)).
+			Msg("wrote partial transcript artifacts with gaps")
+		return result, nil
 	}
 	a.Logger.Info().
 		Str("input", prepared.OriginalPath).
 		Str("output_dir", fileOutputDir).
 		Msg("wrote transcript artifacts")
-	return nil
+	return result, nil
+}
+
+// resolveChunk returns the checkpointed or cached result of a chunk, or
+// transcribes it in a chunk slot of the pool and stores the response.
+func (a *Application) resolveChunk(
+	ctx context.Context,
+	client provider.Client,
+	pool *workerPool,
+	cfg config.Config,
+	workDir string,
+	chunk audio.Chunk,
+) chunkResult {
+	result := chunkResult{chunk: chunk}
+
+	key, keyErr := a.chunkKey(chunk.Path, cfg)
+	if keyErr != nil {
+		a.Logger.Warn().Err(keyErr).Str("file", chunk.Path).Msg("cannot checkpoint chunk")
+	} else if !cfg.Fresh {
+		if response, ok := a.loadCheckpoint(workDir, key); ok {
+			a.Logger.Info().
+				Str("file", chunk.Path).
+				Int("chunk", chunk.Number).
+				Msg("reusing checkpointed chunk result")
+			result.response = response
+			return result
+		}
+		if cfg.Cache {
+			if entry, ok := a.CacheStore(cfg.CacheDir).Get(key); ok {
+				a.Logger.Info().
+					Str("file", chunk.Path).
+					Int("chunk", chunk.Number).
+					Msg("reusing cached chunk result")
+				result.response = provider.Response{Transcript: entry.Transcript, Raw: entry.Raw}
+				return result
+			}
+		}
+	}
+
+	if result.err = pool.chunks.acquire(ctx); result.err != nil {
+		return result
+	}
+	result.response, result.err = a.transcribeChunk(ctx, client, cfg, chunk)
+	pool.chunks.release()
+
+	if result.err == nil && keyErr == nil {
+		if err := a.saveCheckpoint(workDir, key, result.response); err != nil {
+			a.Logger.Warn().Err(err).Str("file", chunk.Path).Msg("failed to checkpoint chunk result")
+		}
+		if cfg.Cache {
+			entry := cache.Entry{Transcript: result.response.Transcript, Raw: result.response.Raw}
+			if err := a.CacheStore(cfg.CacheDir).Put(key, entry); err != nil {
+				a.Logger.Warn().Err(err).Str("file", chunk.Path).Msg("failed to cache chunk result")
+			}
+		}
+	}
+	return result
+}
+
+func (a *Application) transcribeChunk(ctx context.Context, client provider.Client, cfg config.Config, chunk audio.Chunk) (provider.Response, error) {
+	a.Logger.Info().
+		Str("file", chunk.Path).
+		Int("chunk", chunk.Number).
+		Str("provider", string(cfg.Provider)).
+		Str("model", cfg.Model).
+		Msg("transcribing chunk")
+
+	return client.Transcribe(ctx, provider.Request{
+		FilePath:        chunk.Path,
+		Task:            cfg.Task,
+		Model:           cfg.Model,
+		Language:        cfg.Language,
+		Prompt:          cfg.Prompt,
+		WantDiarization: cfg.Outputs.Enabled(domain.ArtifactDiarized),
+		WantWords:       cfg.Outputs.Enabled(domain.ArtifactWords),
+		WantRaw:         cfg.Outputs.Enabled(domain.ArtifactRaw),
+	})
 }
 
 type chunkResult struct {
@@ -209,69 +531,145 @@ type chunkResult struct {
 	err      error
 }
 
-func (a *Application) transcribeChunks(
+// transcribeSource cuts one prepared source into chunks and transcribes them.
+func (a *Application) transcribeSource(
 	ctx context.Context,
 	client provider.Client,
+	pool *workerPool,
 	cfg config.Config,
-	inputPath string,
-	chunks []audio.Chunk,
+	caps domain.Capabilities,
+	prepared audio.PreparedInput,
+	sourcePath string,
+	workDir string,
+	result *fileResult,
 ) (domain.Transcript, [][]byte, error) {
-	workerCount := cfg.Concurrency
-	if workerCount <= 0 {
-		workerCount = runtime.NumCPU()
+	a.Logger.Info().
+		Str("input", prepared.OriginalPath).
+		Str("chunk_source", sourcePath).
+		Str("work_dir", workDir).
+		Int("chunk_seconds", cfg.ChunkSeconds).
+		Str("chunk_mode", string(cfg.ChunkMode)).
+		Int("chunk_overlap", cfg.ChunkOverlap).
+		Int64("max_upload_bytes", caps.MaxUploadBytes).
+		Msg("preparing chunks")
+
+	// A failed chunk cancels the split and the other chunks of this source
+	// unless partial transcripts are allowed.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The split holds an ffmpeg slot until its last segment is written,
+	// while chunks already written are uploaded.
+	if err := pool.ffmpeg.acquire(ctx); err != nil {
+		return domain.Transcript{}, nil, err
 	}
-	if workerCount > len(chunks) {
-		workerCount = len(chunks)
+	chunks := make(chan audio.Chunk)
+	splitErr := make(chan error, 1)
+	go func() {
+		defer pool.ffmpeg.release()
+		splitErr <- a.Audio.StreamChunks(ctx, sourcePath, workDir, audio.ChunkOptions{
+			Seconds:          cfg.ChunkSeconds,
+			Mode:             cfg.ChunkMode,
+			SilenceTolerance: float64(cfg.SilenceTolerance),
+			Overlap:          float64(cfg.ChunkOverlap),
+			MaxBytes:         caps.MaxUploadBytes,
+			UploadFormats:    caps.InputFormats,
+		}, chunks)
+	}()
+
+	transcript, rawArtifacts, err := a.transcribeChunks(ctx, cancel, client, pool, cfg, prepared, workDir, chunks, result)
+	if splitErr := <-splitErr; splitErr != nil {
+		// A split stopped by a failed chunk reports the cancellation; the
+		// chunk error is the cause.
+		if err == nil || !errors.Is(splitErr, context.Canceled) {
+			return domain.Transcript{}, nil, splitErr
+		}
 	}
+	return transcript, rawArtifacts, err
+}
 
-	jobs := make(chan audio.Chunk, len(chunks))
-	results := make(chan chunkResult, len(chunks))
-
-	var wg sync.WaitGroup
-	for idx := 0; idx < workerCount; idx++ {
+// transcribeChunks sends chunks to the provider as they arrive and
+// reassembles the results in chunk order once the channel is closed.
+// Requests run through the shared chunk slots of the pool, so concurrent
+// files never exceed --concurrency in-flight requests together. The first
+// failed chunk calls cancel, so queued and in-flight chunks are not billed
+// for a transcript that is discarded anyway; with cfg.AllowPartial failed
+// chunks become gaps instead.
+func (a *Application) transcribeChunks(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	client provider.Client,
+	pool *workerPool,
+	cfg config.Config,
+	prepared audio.PreparedInput,
+	workDir string,
+	chunks <-chan audio.Chunk,
+	result *fileResult,
+) (domain.Transcript, [][]byte, error) {
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		collected []chunkResult
+		firstErr  error
+	)
+	for chunk := range chunks {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for chunk := range jobs {
-				a.Logger.Info().
-					Str("file", chunk.Path).
-					Int("chunk", chunk.Number).
-					Str("provider", string(cfg.Provider)).
-					Str("model", cfg.Model).
-					Msg("transcribing chunk")
-
-				response, err := client.Transcribe(ctx, provider.Request{
-					FilePath:        chunk.Path,
-					Model:           cfg.Model,
-					Language:        cfg.Language,
-					Prompt:          cfg.Prompt,
-					WantDiarization: cfg.Outputs.Enabled(domain.ArtifactDiarized),
-					WantRaw:         cfg.Outputs.Enabled(domain.ArtifactRaw),
-				})
-				results <- chunkResult{chunk: chunk, response: response, err: err}
+			result := a.resolveChunk(ctx, client, pool, cfg, workDir, chunk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			collected = append(collected, result)
+			if result.err == nil || ctx.Err() != nil {
+				return
+			}
+			a.Logger.Error().Err(result.err).Str("file", chunk.Path).Int("chunk", chunk.Number).Msg("chunk transcription failed")
+			if firstErr == nil {
+				firstErr = fmt.Errorf("chunk %d: %w", chunk.Number, result.err)
+				if !cfg.AllowPartial {
+					cancel()
+				}
 			}
 		}()
 	}
-
-	for _, chunk := range chunks {
-		jobs <- chunk
-	}
-	close(jobs)
-
 	wg.Wait()
-	close(results)
 
-	collected := make([]chunkResult, 0, len(chunks))
-	for result := range results {
-		if result.err != nil {
-			return domain.Transcript{}, nil, fmt.Errorf("chunk %d: %w", result.chunk.Number, result.err)
-		}
-		collected = append(collected, result)
-	}
+	a.Logger.Info().
+		Str("input", prepared.OriginalPath).
+		Int("chunks", len(collected)).
+		Msg("transcribed chunks")
 
 	sort.Slice(collected, func(i, j int) bool {
 		return collected[i].chunk.Number < collected[j].chunk.Number
 	})
+	result.chunks += len(collected)
+	sourceSeconds := 0.0
+	for _, item := range collected {
+		sourceSeconds += item.chunk.Duration - item.chunk.Overlap
+	}
+	if prepared.Speed > 0 {
+		sourceSeconds *= prepared.Speed
+	}
+	// Channels share one timeline, so the longest source is the duration.
+	result.duration = max(result.duration, sourceSeconds)
+
+	failed := 0
+	for _, result := range collected {
+		if result.err == nil {
+			continue
+		}
+		if !cfg.AllowPartial || ctx.Err() != nil {
+			if firstErr != nil {
+				return domain.Transcript{}, nil, firstErr
+			}
+			return domain.Transcript{}, nil, fmt.Errorf("chunk %d: %w", result.chunk.Number, result.err)
+		}
+		failed++
+	}
+	if failed > 0 && failed == len(collected) {
+		return domain.Transcript{}, nil, fmt.Errorf("all %d chunks failed; first error: %w", failed, firstErr)
+	}
 
 	var (
 		combined domain.Transcript
@@ -281,27 +679,99 @@ func (a *Application) transcribeChunks(
 
 	combined.Provider = cfg.Provider
 	combined.Model = cfg.Model
-	combined.Source = inputPath
+	combined.Source = prepared.OriginalPath
 	combined.Language = cfg.Language
+	translated := cfg.Task == domain.TaskTranslate
+
+	speed := 1.0
+	if prepared.Speed > 0 {
+		speed = prepared.Speed
+		combined.Speed = prepared.Speed
+	}
+	scale := func(t float64) float64 { return t * speed }
+	combined.AudioFilter = prepared.Filter
 
+	pieces := make([]stitchPiece, 0, len(collected))
 	for _, item := range collected {
+		if item.err != nil {
+			start := scale(item.chunk.Offset + item.chunk.Overlap/2)
+			end := scale(item.chunk.Offset + item.chunk.Duration)
+			gap := domain.Segment{Start: start, End: max(start, end), Text: gapMarker(item.chunk.Number)}
+			combined.FailedChunks = append(combined.FailedChunks, domain.FailedChunk{
+				Number: item.chunk.Number,
+				Start:  gap.Start,
+				End:    gap.End,
+				Error:  item.err.Error(),
+			})
+			pieces = append(pieces, stitchPiece{
+				offset:   scale(item.chunk.Offset),
+				overlap:  scale(item.chunk.Overlap),
+				text:     gap.Text,
+				segments: []domain.Segment{gap},
+			})
+			continue
+		}
 		piece := item.response.Transcript
-		if strings.TrimSpace(piece.Language) != "" {
-			combined.Language = strings.TrimSpace(piece.Language)
+		if language := strings.TrimSpace(piece.Language); language != "" {
+			if translated {
+				// /audio/translations takes no language and reports the
+				// one it detected.
+				combined.SourceLanguage = language
+			} else {
+				combined.Language = language
+			}
 		}
-		if text := strings.TrimSpace(piece.PlainText()); text != "" {
-			texts = append(texts, text)
+		if speed != 1 {
+			// Providers report sped-up time; rescale to real time before shifting.
+			piece.Segments = domain.RetimeSegments(piece.Segments, scale, scale)
+			piece.SpeakerSegments = domain.RetimeSpeakerSegments(piece.SpeakerSegments, scale, scale)
+			piece.Words = domain.RetimeWords(piece.Words, scale, scale)
 		}
+		offset := scale(item.chunk.Offset)
 
-		combined.Segments = append(combined.Segments, domain.ShiftSegments(piece.Segments, item.chunk.Offset)...)
-		combined.SpeakerSegments = append(combined.SpeakerSegments, domain.ShiftSpeakerSegments(piece.SpeakerSegments, item.chunk.Offset)...)
+		pieces = append(pieces, stitchPiece{
+			offset:   offset,
+			overlap:  scale(item.chunk.Overlap),
+			text:     strings.TrimSpace(piece.PlainText()),
+			segments: domain.ShiftSegments(piece.Segments, offset),
+			speakers: domain.ShiftSpeakerSegments(piece.SpeakerSegments, offset),
+			words:    domain.ShiftWords(piece.Words, offset),
+		})
 
 		if cfg.Outputs.Enabled(domain.ArtifactRaw) && len(item.response.Raw) > 0 {
 			rawItems = append(rawItems, item.response.Raw)
 		}
 	}
+	stitchOverlaps(pieces)
+
+	for _, piece := range pieces {
+		if piece.text != "" {
+			texts = append(texts, piece.text)
+		}
+		combined.Segments = append(combined.Segments, piece.segments...)
+		combined.SpeakerSegments = append(combined.SpeakerSegments, piece.speakers...)
+		combined.Words = append(combined.Words, piece.words...)
+	}
+
+	if len(prepared.TimeMap) > 0 {
+		// Chunk offsets are on the prepared source, which may skip silences
+		// or whole ranges; map everything back so subtitles line up with the input.
+		start, end := prepared.TimeMap.Original, prepared.TimeMap.OriginalEnd
+		combined.Segments = domain.RetimeSegments(combined.Segments, start, end)
+		combined.SpeakerSegments = domain.RetimeSpeakerSegments(combined.SpeakerSegments, start, end)
+		combined.Words = domain.RetimeWords(combined.Words, start, end)
+		for idx := range combined.FailedChunks {
+			failed := &combined.FailedChunks[idx]
+			failed.Start, failed.End = start(failed.Start), end(failed.End)
+		}
+	}
 
 	combined.Text = strings.Join(texts, "\n")
+	if translated {
+		combined.Translated = true
+		combined.TargetLanguage = domain.TranslationTargetLanguage
+		combined.Language = domain.TranslationTargetLanguage
+	}
 
 	if cfg.Outputs.Enabled(domain.ArtifactTimestamps) || cfg.Outputs.Enabled(domain.ArtifactSRT) || cfg.Outputs.Enabled(domain.ArtifactVTT) {
 		if len(combined.Segments) == 0 {
@@ -311,6 +781,15 @@ func (a *Application) transcribeChunks(
 	if cfg.Outputs.Enabled(domain.ArtifactDiarized) && len(combined.SpeakerSegments) == 0 {
 		return domain.Transcript{}, nil, errors.New("requested diarized artifact but provider returned no speaker segments")
 	}
+	if cfg.Outputs.Enabled(domain.ArtifactWords) && len(combined.Words) == 0 {
+		return domain.Transcript{}, nil, errors.New("requested words artifact but provider returned no word timestamps")
+	}
 
 	return combined, rawItems, nil
 }
+
+// gapMarker is the text that stands in for a failed chunk in a partial
+// transcript; its time range is in the segment and in failed_chunks.
+func gapMarker(number int) string {
+	return fmt.Sprintf("[gap: chunk %d failed]", number)
+}
@@ -8,8 +8,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/arykalin/whisper-cli/internal/audio"
 	"github.com/arykalin/whisper-cli/internal/config"
@@ -22,18 +25,22 @@ import (
 type prepareInputCall struct {
 	inputFile string
 	workDir   string
+	opts      audio.InputOptions
 }
 
 type prepareChunksCall struct {
-	inputFile    string
-	workDir      string
-	chunkSeconds int
+	inputFile string
+	workDir   string
+	opts      audio.ChunkOptions
 }
 
 type fakeAudioPipeline struct {
 	mediaFiles         []string
 	preparedInput      audio.PreparedInput
 	chunks             []audio.Chunk
+	sourceChunks       map[string][]audio.Chunk
+	audioStreams       []audio.AudioStream
+	undecodable        map[string]string
 	ensureErr          error
 	collectErr         error
 	prepareInputErr    error
@@ -42,27 +49,53 @@ type fakeAudioPipeline struct {
 	prepareInputCalls  []prepareInputCall
 	prepareChunksCalls []prepareChunksCall
 	callOrder          []string
+	mu                 sync.Mutex
+}
+
+// calls returns a copy of the call order that is safe to read while the
+// pipeline is in use.
+func (f *fakeAudioPipeline) calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.callOrder...)
 }
 
 func (f *fakeAudioPipeline) EnsureBinaries() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.callOrder = append(f.callOrder, "ensure")
 	return f.ensureErr
 }
 
-func (f *fakeAudioPipeline) CollectMediaFiles(dir string) ([]string, error) {
+func (f *fakeAudioPipeline) CollectMediaFiles(_ context.Context, dir string, _ audio.ScanOptions) ([]string, []audio.SkippedFile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.callOrder = append(f.callOrder, "collect")
 	f.collectCalls = append(f.collectCalls, dir)
 	if f.collectErr != nil {
-		return nil, f.collectErr
+		return nil, nil, f.collectErr
 	}
-	return f.mediaFiles, nil
+	return f.mediaFiles, nil, nil
 }
 
-func (f *fakeAudioPipeline) PrepareInput(_ context.Context, inputFile string, workDir string) (audio.PreparedInput, error) {
+func (f *fakeAudioPipeline) DetectMedia(_ context.Context, path string) (bool, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.callOrder = append(f.callOrder, "detect:"+filepath.Base(path))
+	if reason, ok := f.undecodable[path]; ok {
+		return false, reason
+	}
+	return true, ""
+}
+
+func (f *fakeAudioPipeline) PrepareInput(_ context.Context, inputFile string, workDir string, opts audio.InputOptions) (audio.PreparedInput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.callOrder = append(f.callOrder, "prepare_input")
 	f.prepareInputCalls = append(f.prepareInputCalls, prepareInputCall{
 		inputFile: inputFile,
 		workDir:   workDir,
+		opts:      opts,
 	})
 	if f.prepareInputErr != nil {
 		return audio.PreparedInput{}, f.prepareInputErr
@@ -76,17 +109,36 @@ func (f *fakeAudioPipeline) PrepareInput(_ context.Context, inputFile string, wo
 	return f.preparedInput, nil
 }
 
-func (f *fakeAudioPipeline) PrepareChunks(_ context.Context, inputFile string, workDir string, chunkSeconds int) ([]audio.Chunk, error) {
-	f.callOrder = append(f.callOrder, "prepare_chunks")
+func (f *fakeAudioPipeline) StreamChunks(_ context.Context, inputFile string, workDir string, opts audio.ChunkOptions, out chan<- audio.Chunk) error {
+	defer close(out)
+
+	f.mu.Lock()
+	f.callOrder = append(f.callOrder, "stream_chunks")
 	f.prepareChunksCalls = append(f.prepareChunksCalls, prepareChunksCall{
-		inputFile:    inputFile,
-		workDir:      workDir,
-		chunkSeconds: chunkSeconds,
+		inputFile: inputFile,
+		workDir:   workDir,
+		opts:      opts,
 	})
+	chunks, ok := f.sourceChunks[inputFile]
+	if !ok {
+		chunks = f.chunks
+	}
+	f.mu.Unlock()
+
 	if f.prepareChunksErr != nil {
-		return nil, f.prepareChunksErr
+		return f.prepareChunksErr
+	}
+	for _, chunk := range chunks {
+		out <- chunk
 	}
-	return f.chunks, nil
+	return nil
+}
+
+func (f *fakeAudioPipeline) ListAudioStreams(_ context.Context, inputFile string) ([]audio.AudioStream, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.callOrder = append(f.callOrder, "list_audio_streams")
+	return f.audioStreams, nil
 }
 
 type fakeProvider struct {
@@ -210,7 +262,7 @@ func TestApplicationRunWritesMergedArtifactsInChunkOrder(t *testing.T) {
 		t.Fatalf("Run returned error: %v", err)
 	}
 
-	if strings.Join(audioPipeline.callOrder, ",") != "ensure,prepare_input,prepare_chunks" {
+	if strings.Join(audioPipeline.callOrder, ",") != "ensure,prepare_input,stream_chunks" {
 		t.Fatalf("unexpected call order: %v", audioPipeline.callOrder)
 	}
 	if len(audioPipeline.prepareInputCalls) != 1 {
@@ -226,10 +278,10 @@ func TestApplicationRunWritesMergedArtifactsInChunkOrder(t *testing.T) {
 		t.Fatalf("prepare chunks calls = %d, want 1", len(audioPipeline.prepareChunksCalls))
 	}
 	if audioPipeline.prepareChunksCalls[0].inputFile != filepath.Join(workDir, "source.m4a") {
-		t.Fatalf("PrepareChunks input = %s", audioPipeline.prepareChunksCalls[0].inputFile)
+		t.Fatalf("StreamChunks input = %s", audioPipeline.prepareChunksCalls[0].inputFile)
 	}
 	if audioPipeline.prepareChunksCalls[0].workDir != workDir {
-		t.Fatalf("PrepareChunks workDir = %s, want %s", audioPipeline.prepareChunksCalls[0].workDir, workDir)
+		t.Fatalf("StreamChunks workDir = %s, want %s", audioPipeline.prepareChunksCalls[0].workDir, workDir)
 	}
 
 	outDir := filepath.Join(outputRoot, "input")
@@ -277,6 +329,195 @@ func TestApplicationRunWritesMergedArtifactsInChunkOrder(t *testing.T) {
 	}
 }
 
+func TestApplicationRunWritesShiftedWords(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	app := &Application{
+		FS: fsx.OS{},
+		Audio: &fakeAudioPipeline{
+			chunks: []audio.Chunk{
+				{Number: 0, Path: "chunk-0", Offset: 0},
+				{Number: 1, Path: "chunk-1", Offset: 600},
+			},
+		},
+		Registry: provider.NewRegistry(fakeProvider{
+			name: domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{
+				"whisper-1": {SupportsSegmentTimestamps: true, SupportsWordTimestamps: true},
+			},
+			responses: map[string]provider.Response{
+				"chunk-0": {Transcript: domain.Transcript{
+					Text:     "hello",
+					Segments: []domain.Segment{{Start: 0, End: 1, Text: "hello", Words: []domain.Word{{Start: 0.2, End: 0.6, Word: "hello"}}}},
+					Words:    []domain.Word{{Start: 0.2, End: 0.6, Word: "hello"}},
+				}},
+				"chunk-1": {Transcript: domain.Transcript{
+					Text:     "world",
+					Segments: []domain.Segment{{Start: 1, End: 2, Text: "world", Words: []domain.Word{{Start: 1.5, End: 1.9, Word: "world"}}}},
+					Words:    []domain.Word{{Start: 1.5, End: 1.9, Word: "world"}},
+				}},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+
+	outputRoot := filepath.Join(dir, "out")
+	err := app.Run(context.Background(), config.Config{
+		Input:        input,
+		OutputDir:    outputRoot,
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.ArtifactSet{domain.ArtifactWords: true},
+		ChunkSeconds: 600,
+		Concurrency:  2,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputRoot, "input", "words.json"))
+	if err != nil {
+		t.Fatalf("read words.json: %v", err)
+	}
+	var words []domain.Word
+	if err := json.Unmarshal(data, &words); err != nil {
+		t.Fatalf("unmarshal words.json: %v", err)
+	}
+	if len(words) != 2 || words[1].Word != "world" || words[1].Start != 601.5 || words[1].End != 601.9 {
+		t.Fatalf("words = %#v", words)
+	}
+}
+
+func TestApplicationRunRejectsWordsWithoutCapability(t *testing.T) {
+	t.Parallel()
+
+	app := &Application{
+		FS:    fsx.OS{},
+		Audio: &fakeAudioPipeline{},
+		Registry: provider.NewRegistry(fakeProvider{
+			name: domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{
+				"gpt-4o-transcribe": {},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+
+	err := app.Run(context.Background(), config.Config{
+		Input:        "input.m4a",
+		OutputDir:    "output",
+		Provider:     domain.ProviderOpenAI,
+		Model:        "gpt-4o-transcribe",
+		Outputs:      domain.ArtifactSet{domain.ArtifactWords: true},
+		ChunkSeconds: 600,
+		Concurrency:  1,
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not support word timestamps") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestApplicationRunMarksTranslatedTranscript(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	app := &Application{
+		FS: fsx.OS{},
+		Audio: &fakeAudioPipeline{
+			chunks: []audio.Chunk{{Number: 0, Path: "chunk-0", Offset: 0}},
+		},
+		Registry: provider.NewRegistry(fakeProvider{
+			name: domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{
+				"whisper-1": {SupportsSegmentTimestamps: true, SupportsTranslation: true},
+			},
+			responses: map[string]provider.Response{
+				"chunk-0": {Transcript: domain.Transcript{
+					Text:     "hello",
+					Language: "english",
+					Segments: []domain.Segment{{Start: 0, End: 1, Text: "hello"}},
+				}},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+
+	outputRoot := filepath.Join(dir, "out")
+	err := app.Run(context.Background(), config.Config{
+		Task:         domain.TaskTranslate,
+		Input:        input,
+		OutputDir:    outputRoot,
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.DefaultArtifacts(),
+		ChunkSeconds: 600,
+		Concurrency:  1,
+		Language:     "ru",
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputRoot, "input", "transcript.json"))
+	if err != nil {
+		t.Fatalf("read transcript.json: %v", err)
+	}
+	var transcript domain.Transcript
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		t.Fatalf("unmarshal transcript.json: %v", err)
+	}
+	// The configured language is never sent with a translation, so the
+	// source language is the one the provider detected.
+	if !transcript.Translated || transcript.SourceLanguage != "english" || transcript.TargetLanguage != "en" || transcript.Language != "en" {
+		t.Fatalf("transcript = %#v", transcript)
+	}
+}
+
+func TestApplicationRunRejectsTranslationWithoutCapability(t *testing.T) {
+	t.Parallel()
+
+	app := &Application{
+		FS:    fsx.OS{},
+		Audio: &fakeAudioPipeline{},
+		Registry: provider.NewRegistry(fakeProvider{
+			name: domain.ProviderGroq,
+			capabilities: map[string]domain.Capabilities{
+				"whisper-large-v3-turbo": {SupportsSegmentTimestamps: true},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+
+	err := app.Run(context.Background(), config.Config{
+		Task:         domain.TaskTranslate,
+		Input:        "input.m4a",
+		OutputDir:    "output",
+		Provider:     domain.ProviderGroq,
+		Model:        "whisper-large-v3-turbo",
+		Outputs:      domain.DefaultArtifacts(),
+		ChunkSeconds: 600,
+		Concurrency:  1,
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not support translation") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestApplicationRunDisablesUnsupportedTimestampArtifacts(t *testing.T) {
 	t.Parallel()
 
@@ -431,3 +672,542 @@ func TestApplicationRunRejectsEmptyMediaDirectory(t *testing.T) {
 		t.Fatalf("collect calls = %v, want [%s]", audioPipeline.collectCalls, inputDir)
 	}
 }
+
+func TestCompatibleClientsLoadFileAndInlineDefinitions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	definitionPath := filepath.Join(dir, "providers.json")
+	if err := os.WriteFile(definitionPath, []byte(`{"providers":[{"name":"whispercpp","base_url":"http://127.0.0.1:8080/v1","models":{"large-v3":{"segment_timestamps":true}}}]}`), 0o644); err != nil {
+		t.Fatalf("write definitions: %v", err)
+	}
+
+	env := staticEnv{
+		"WHISPER_CLI_PROVIDERS_FILE": definitionPath,
+		"WHISPER_CLI_PROVIDERS":      `{"providers":[{"name":"vllm","base_url":"https://vllm.internal/v1","api_key_env":"VLLM_KEY","models":{"whisper":{}}}]}`,
+	}
+	clients, err := compatibleClients(fsx.OS{}, env, zerolog.New(io.Discard))
+	if err != nil {
+		t.Fatalf("compatibleClients returned error: %v", err)
+	}
+	if len(clients) != 2 || clients[0].Name() != "whispercpp" || clients[1].Name() != "vllm" {
+		t.Fatalf("clients = %v", clients)
+	}
+	if err := clients[1].Preflight(); err == nil || !strings.Contains(err.Error(), "VLLM_KEY") {
+		t.Fatalf("expected preflight error for missing VLLM_KEY, got %v", err)
+	}
+
+	env["WHISPER_CLI_PROVIDERS"] = `{"providers":[{"name":"whispercpp","base_url":"http://other/v1","models":{"m":{}}}]}`
+	if _, err := compatibleClients(fsx.OS{}, env, zerolog.New(io.Discard)); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Fatalf("expected duplicate provider error, got %v", err)
+	}
+}
+
+func TestApplicationRunReportsBrokenProviderDefinitions(t *testing.T) {
+	t.Parallel()
+
+	env := staticEnv{
+		"WHISPER_CLI_PROVIDERS": `{"providers":[{"name":"myllm","base_url":"http://127.0.0.1:8080/v1","modles":{"large-v3":{}}}]}`,
+	}
+	_, providersErr := compatibleClients(fsx.OS{}, env, zerolog.New(io.Discard))
+	if providersErr == nil {
+		t.Fatal("expected an error for the misspelled field")
+	}
+
+	app := &Application{
+		FS:           fsx.OS{},
+		Audio:        &fakeAudioPipeline{},
+		Registry:     provider.NewRegistry(fakeProvider{name: domain.ProviderOpenAI}),
+		Logger:       zerolog.New(io.Discard),
+		Env:          env,
+		ProvidersErr: providersErr,
+	}
+	err := app.Run(context.Background(), config.Config{
+		Input:        filepath.Join(t.TempDir(), "input.m4a"),
+		OutputDir:    t.TempDir(),
+		Provider:     "myllm",
+		Model:        "large-v3",
+		ChunkSeconds: 600,
+		Concurrency:  1,
+	})
+	if ClassifyError(err) != ErrorClassConfig {
+		t.Fatalf("expected a config error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "WHISPER_CLI_PROVIDERS") || !strings.Contains(err.Error(), `unknown field "modles"`) {
+		t.Fatalf("expected the definition error in %q", err)
+	}
+}
+
+func TestApplicationRunRequiresModelForMultiModelProvider(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	app := &Application{
+		FS:    fsx.OS{},
+		Audio: &fakeAudioPipeline{},
+		Registry: provider.NewRegistry(fakeProvider{
+			name: "localai",
+			capabilities: map[string]domain.Capabilities{
+				"large": {},
+				"small": {},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+
+	err := app.Run(context.Background(), config.Config{
+		Input:        input,
+		OutputDir:    filepath.Join(dir, "out"),
+		Provider:     "localai",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 600,
+		Concurrency:  1,
+	})
+	if err == nil || !strings.Contains(err.Error(), "requires --model; supported models: large, small") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestApplicationRunUsesProviderDefaultAudioProfile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	audioPipeline := &fakeAudioPipeline{
+		chunks: []audio.Chunk{{Number: 0, Path: "chunk-0", Offset: 0}},
+	}
+	app := &Application{
+		FS:    fsx.OS{},
+		Audio: audioPipeline,
+		Registry: provider.NewRegistry(fakeProvider{
+			name: domain.ProviderGroq,
+			capabilities: map[string]domain.Capabilities{
+				"whisper-large-v3": {SupportsSegmentTimestamps: true, AudioProfile: "speech-flac", InputFormats: []string{"flac", "m4a"}},
+			},
+			responses: map[string]provider.Response{
+				"chunk-0": {Transcript: domain.Transcript{Text: "hello"}},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+
+	err := app.Run(context.Background(), config.Config{
+		Input:        input,
+		OutputDir:    filepath.Join(dir, "out"),
+		Provider:     domain.ProviderGroq,
+		Model:        "whisper-large-v3",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 600,
+		Concurrency:  1,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if got := audioPipeline.prepareInputCalls[0].opts.Profile.Name; got != audio.ProfileSpeechFLAC {
+		t.Fatalf("PrepareInput profile = %q, want %q", got, audio.ProfileSpeechFLAC)
+	}
+}
+
+func TestApplicationRunRejectsProfileFormatNotAcceptedByProvider(t *testing.T) {
+	t.Parallel()
+
+	app := &Application{
+		FS:    fsx.OS{},
+		Audio: &fakeAudioPipeline{},
+		Registry: provider.NewRegistry(fakeProvider{
+			name: domain.ProviderGroq,
+			capabilities: map[string]domain.Capabilities{
+				"whisper-large-v3": {SupportsSegmentTimestamps: true, InputFormats: []string{"flac", "m4a"}},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+
+	err := app.Run(context.Background(), config.Config{
+		Input:        "input.m4a",
+		OutputDir:    "output",
+		Provider:     domain.ProviderGroq,
+		Model:        "whisper-large-v3",
+		Outputs:      domain.DefaultArtifacts(),
+		ChunkSeconds: 600,
+		AudioProfile: audio.ProfileSpeechOpus,
+		Concurrency:  1,
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not accept ogg uploads") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestApplicationRunMapsCompactedTimestampsBack(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	app := &Application{
+		FS: fsx.OS{},
+		Audio: &fakeAudioPipeline{
+			preparedInput: audio.PreparedInput{
+				OriginalPath:    input,
+				ChunkSourcePath: filepath.Join(dir, "source.m4a"),
+				Converted:       true,
+				TimeMap: audio.TimeMap{
+					{Compact: 0, Original: 0, Duration: 10},
+					{Compact: 10, Original: 70, Duration: 50},
+				},
+			},
+			chunks: []audio.Chunk{{Number: 0, Path: "chunk-0", Offset: 0}},
+		},
+		Registry: provider.NewRegistry(fakeProvider{
+			name: domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{
+				"gpt-4o-transcribe-diarize": {SupportsDiarization: true},
+			},
+			responses: map[string]provider.Response{
+				"chunk-0": {Transcript: domain.Transcript{
+					Text: "hello world",
+					SpeakerSegments: []domain.SpeakerSegment{
+						{Start: 2, End: 10, Speaker: "A", Text: "hello"},
+						{Start: 10, End: 15, Speaker: "B", Text: "world"},
+					},
+				}},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+
+	outputRoot := filepath.Join(dir, "out")
+	err := app.Run(context.Background(), config.Config{
+		Input:         input,
+		OutputDir:     outputRoot,
+		Provider:      domain.ProviderOpenAI,
+		Model:         "gpt-4o-transcribe-diarize",
+		Outputs:       domain.ArtifactSet{},
+		ChunkSeconds:  600,
+		RemoveSilence: 3,
+		Concurrency:   1,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputRoot, "input", "transcript.json"))
+	if err != nil {
+		t.Fatalf("read transcript.json: %v", err)
+	}
+	var transcript domain.Transcript
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		t.Fatalf("unmarshal transcript.json: %v", err)
+	}
+	got := transcript.SpeakerSegments
+	if len(got) != 2 || got[0].Start != 2 || got[0].End != 10 || got[1].Start != 70 || got[1].End != 75 {
+		t.Fatalf("speaker segments = %#v", got)
+	}
+}
+
+func TestApplicationRunRescalesSpedUpTimestamps(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	app := &Application{
+		FS: fsx.OS{},
+		Audio: &fakeAudioPipeline{
+			preparedInput: audio.PreparedInput{
+				OriginalPath:    input,
+				ChunkSourcePath: filepath.Join(dir, "source.m4a"),
+				Converted:       true,
+				Speed:           1.5,
+				Filter:          "afftdn=nf=-25",
+			},
+			chunks: []audio.Chunk{
+				{Number: 0, Path: "chunk-0", Offset: 0},
+				{Number: 1, Path: "chunk-1", Offset: 400},
+			},
+		},
+		Registry: provider.NewRegistry(fakeProvider{
+			name: domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{
+				"whisper-1": {SupportsSegmentTimestamps: true},
+			},
+			responses: map[string]provider.Response{
+				"chunk-0": {Transcript: domain.Transcript{Text: "hello", Segments: []domain.Segment{{Start: 2, End: 4, Text: "hello"}}}},
+				"chunk-1": {Transcript: domain.Transcript{Text: "world", Segments: []domain.Segment{{Start: 10, End: 12, Text: "world"}}}},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+
+	outputRoot := filepath.Join(dir, "out")
+	err := app.Run(context.Background(), config.Config{
+		Input:        input,
+		OutputDir:    outputRoot,
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 400,
+		Speed:        1.5,
+		Concurrency:  1,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputRoot, "input", "transcript.json"))
+	if err != nil {
+		t.Fatalf("read transcript.json: %v", err)
+	}
+	var transcript domain.Transcript
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		t.Fatalf("unmarshal transcript.json: %v", err)
+	}
+	if transcript.Speed != 1.5 || transcript.AudioFilter != "afftdn=nf=-25" {
+		t.Fatalf("speed = %v, audio_filter = %q", transcript.Speed, transcript.AudioFilter)
+	}
+	got := transcript.Segments
+	if len(got) != 2 || got[0].Start != 3 || got[0].End != 6 || got[1].Start != 615 || got[1].End != 618 {
+		t.Fatalf("segments = %#v", got)
+	}
+}
+
+func TestApplicationRunMergesChannelsIntoSpeakerSegments(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "call.wav")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	audioPipeline := &fakeAudioPipeline{
+		preparedInput: audio.PreparedInput{
+			OriginalPath:    input,
+			ChunkSourcePath: "left.m4a",
+			Converted:       true,
+			ChannelPaths:    []string{"left.m4a", "right.m4a"},
+		},
+		sourceChunks: map[string][]audio.Chunk{
+			"left.m4a":  {{Number: 0, Path: "left-0", Offset: 0}},
+			"right.m4a": {{Number: 0, Path: "right-0", Offset: 0}},
+		},
+	}
+	app := &Application{
+		FS:    fsx.OS{},
+		Audio: audioPipeline,
+		Registry: provider.NewRegistry(fakeProvider{
+			name: domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{
+				"whisper-1": {SupportsSegmentTimestamps: true},
+			},
+			responses: map[string]provider.Response{
+				"left-0": {Transcript: domain.Transcript{Segments: []domain.Segment{
+					{Start: 0, End: 2, Text: "Hello, how can I help?"},
+					{Start: 6, End: 7, Text: "Sure."},
+				}}},
+				"right-0": {Transcript: domain.Transcript{Segments: []domain.Segment{
+					{Start: 3, End: 5, Text: "My order is late."},
+				}}},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+
+	outputRoot := filepath.Join(dir, "out")
+	err := app.Run(context.Background(), config.Config{
+		Input:         input,
+		OutputDir:     outputRoot,
+		Provider:      domain.ProviderOpenAI,
+		Model:         "whisper-1",
+		Outputs:       domain.ArtifactSet{domain.ArtifactDiarized: true},
+		ChunkSeconds:  600,
+		ChannelLabels: []string{"agent", "customer"},
+		Concurrency:   1,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if got := audioPipeline.prepareInputCalls[0].opts.Channels; got != 2 {
+		t.Fatalf("PrepareInput channels = %d, want 2", got)
+	}
+	if got := audioPipeline.prepareChunksCalls[1].workDir; got != filepath.Join(outputRoot, "call", "_work", "channel_1") {
+		t.Fatalf("channel work dir = %s", got)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputRoot, "call", "transcript.json"))
+	if err != nil {
+		t.Fatalf("read transcript.json: %v", err)
+	}
+	var transcript domain.Transcript
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		t.Fatalf("unmarshal transcript.json: %v", err)
+	}
+	speakers := transcript.SpeakerSegments
+	if len(speakers) != 3 || speakers[0].Speaker != "agent" || speakers[1].Speaker != "customer" || speakers[2].Speaker != "agent" {
+		t.Fatalf("speaker segments = %#v", speakers)
+	}
+	wantText := "agent: Hello, how can I help?\ncustomer: My order is late.\nagent: Sure."
+	if transcript.Text != wantText {
+		t.Fatalf("text = %q, want %q", transcript.Text, wantText)
+	}
+}
+
+// failFastProvider fails one chunk and holds every other request until it
+// is cancelled.
+type failFastProvider struct {
+	fakeProvider
+	failing   string
+	mu        sync.Mutex
+	cancelled int
+}
+
+func (p *failFastProvider) Transcribe(ctx context.Context, req provider.Request) (provider.Response, error) {
+	if req.FilePath == p.failing {
+		return provider.Response{}, errors.New("invalid audio")
+	}
+	select {
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.cancelled++
+		p.mu.Unlock()
+		return provider.Response{}, ctx.Err()
+	case <-time.After(5 * time.Second):
+		return provider.Response{}, errors.New("request was not cancelled")
+	}
+}
+
+func TestApplicationRunCancelsChunksAfterFirstFailure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	var chunks []audio.Chunk
+	for idx := range 4 {
+		chunks = append(chunks, audio.Chunk{Number: idx, Path: "chunk_" + strconv.Itoa(idx), Offset: float64(idx) * 600})
+	}
+	client := &failFastProvider{
+		fakeProvider: fakeProvider{
+			name:         domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{"whisper-1": {}},
+		},
+		failing: "chunk_2",
+	}
+	app := &Application{
+		FS:       fsx.OS{},
+		Audio:    &fakeAudioPipeline{chunks: chunks},
+		Registry: provider.NewRegistry(client),
+		Logger:   zerolog.New(io.Discard),
+		Env:      staticEnv{},
+	}
+
+	started := time.Now()
+	err := app.Run(context.Background(), config.Config{
+		Input:        input,
+		OutputDir:    filepath.Join(dir, "out"),
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 600,
+		Concurrency:  4,
+	})
+	if err == nil || !strings.Contains(err.Error(), "chunk 2: invalid audio") {
+		t.Fatalf("expected the chunk 2 error, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Fatalf("Run took %s; remaining chunks were not cancelled", elapsed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "input", "transcript.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected no transcript after a failed chunk, stat err = %v", err)
+	}
+}
+
+func TestApplicationRunAllowPartialWritesGapMarkers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	chunks := []audio.Chunk{
+		{Number: 0, Path: "chunk_0", Offset: 0, Duration: 600},
+		{Number: 1, Path: "chunk_1", Offset: 600, Duration: 600},
+		{Number: 2, Path: "chunk_2", Offset: 1200, Duration: 300},
+	}
+	app := &Application{
+		FS:    fsx.OS{},
+		Audio: &fakeAudioPipeline{chunks: chunks},
+		Registry: provider.NewRegistry(fakeProvider{
+			name:         domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{"whisper-1": {SupportsSegmentTimestamps: true}},
+			responses: map[string]provider.Response{
+				"chunk_0": {Transcript: domain.Transcript{Text: "first", Segments: []domain.Segment{{Start: 0, End: 5, Text: "first"}}}},
+				"chunk_2": {Transcript: domain.Transcript{Text: "third", Segments: []domain.Segment{{Start: 0, End: 5, Text: "third"}}}},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+	outputRoot := filepath.Join(dir, "out")
+	err := app.Run(context.Background(), config.Config{
+		Input:        input,
+		OutputDir:    outputRoot,
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.DefaultArtifacts(),
+		ChunkSeconds: 600,
+		Concurrency:  1,
+		AllowPartial: true,
+	})
+	if ClassifyError(err) != ErrorClassPartial {
+		t.Fatalf("expected a partial run error, got %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputRoot, "input", "transcript.json"))
+	if err != nil {
+		t.Fatalf("read transcript.json: %v", err)
+	}
+	var transcript domain.Transcript
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		t.Fatalf("unmarshal transcript.json: %v", err)
+	}
+	if transcript.Text != "first\n[gap: chunk 1 failed]\nthird" {
+		t.Fatalf("text = %q", transcript.Text)
+	}
+	if len(transcript.FailedChunks) != 1 {
+		t.Fatalf("failed chunks = %#v", transcript.FailedChunks)
+	}
+	failed := transcript.FailedChunks[0]
+	if failed.Number != 1 || failed.Start != 600 || failed.End != 1200 || !strings.Contains(failed.Error, "missing fake response") {
+		t.Fatalf("failed chunk = %#v", failed)
+	}
+	if len(transcript.Segments) != 3 || transcript.Segments[1].Start != 600 || transcript.Segments[2].Start != 1200 {
+		t.Fatalf("segments = %#v", transcript.Segments)
+	}
+}
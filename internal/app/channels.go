@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/provider"
+)
+
+// transcribeChannels transcribes every split channel on its own and merges
+// the results into speaker segments labelled by channel.
+func (a *Application) transcribeChannels(
+	ctx context.Context,
+	client provider.Client,
+	pool *workerPool,
+	cfg config.Config,
+	caps domain.Capabilities,
+	prepared audio.PreparedInput,
+	workDir string,
+	result *fileResult,
+) (domain.Transcript, [][]byte, error) {
+	channelCfg := cfg
+	channelCfg.Outputs = maps.Clone(cfg.Outputs)
+	delete(channelCfg.Outputs, domain.ArtifactDiarized)
+
+	var (
+		transcripts  []domain.Transcript
+		rawArtifacts [][]byte
+	)
+	for channel, sourcePath := range prepared.ChannelPaths {
+		channelWorkDir := filepath.Join(workDir, fmt.Sprintf("channel_%d", channel))
+		transcript, raw, err := a.transcribeSource(ctx, client, pool, channelCfg, caps, prepared, sourcePath, channelWorkDir, result)
+		if err != nil {
+			return domain.Transcript{}, nil, fmt.Errorf("channel %s: %w", cfg.ChannelLabels[channel], err)
+		}
+		if len(transcript.Segments) == 0 && strings.TrimSpace(transcript.Text) != "" {
+			return domain.Transcript{}, nil, fmt.Errorf("channel %s: provider returned no segments to merge", cfg.ChannelLabels[channel])
+		}
+		for idx := range transcript.FailedChunks {
+			transcript.FailedChunks[idx].Channel = cfg.ChannelLabels[channel]
+		}
+		transcripts = append(transcripts, transcript)
+		rawArtifacts = append(rawArtifacts, raw...)
+	}
+
+	return mergeChannels(transcripts, cfg.ChannelLabels), rawArtifacts, nil
+}
+
+// mergeChannels interleaves per-channel transcripts by time. Every segment
+// becomes a speaker segment named after its channel label.
+func mergeChannels(transcripts []domain.Transcript, labels []string) domain.Transcript {
+	merged := transcripts[0]
+	merged.Segments = nil
+	merged.SpeakerSegments = nil
+	merged.Words = nil
+
+	var failedChunks []domain.FailedChunk
+	for channel, transcript := range transcripts {
+		for _, segment := range transcript.Segments {
+			merged.SpeakerSegments = append(merged.SpeakerSegments, domain.SpeakerSegment{
+				Start:   segment.Start,
+				End:     segment.End,
+				Speaker: labels[channel],
+				Text:    strings.TrimSpace(segment.Text),
+			})
+		}
+		merged.Segments = append(merged.Segments, transcript.Segments...)
+		merged.Words = append(merged.Words, transcript.Words...)
+		failedChunks = append(failedChunks, transcript.FailedChunks...)
+	}
+	merged.FailedChunks = failedChunks
+
+	sort.SliceStable(merged.SpeakerSegments, func(i, j int) bool {
+		return merged.SpeakerSegments[i].Start < merged.SpeakerSegments[j].Start
+	})
+	sort.SliceStable(merged.Segments, func(i, j int) bool {
+		return merged.Segments[i].Start < merged.Segments[j].Start
+	})
+	sort.SliceStable(merged.Words, func(i, j int) bool {
+		return merged.Words[i].Start < merged.Words[j].Start
+	})
+
+	lines := make([]string, 0, len(merged.SpeakerSegments))
+	for _, segment := range merged.SpeakerSegments {
+		if segment.Text != "" {
+			lines = append(lines, segment.Speaker+": "+segment.Text)
+		}
+	}
+	merged.Text = strings.Join(lines, "\n")
+	return merged
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/cache"
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/provider"
+)
+
+// checkpointDir holds per-chunk provider results inside a work directory,
+// so a rerun after a failure or Ctrl-C only pays for the missing chunks.
+const checkpointDir = "checkpoints"
+
+// chunkKey identifies a chunk result by the chunk audio, the preprocessing
+// that produced it and every request setting that changes the provider
+// response. Checkpoints and the shared cache both use it.
+func (a *Application) chunkKey(chunkPath string, cfg config.Config) (string, error) {
+	file, err := a.FS.Open(chunkPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	audioHash := sha256.New()
+	if _, err := io.Copy(audioHash, file); err != nil {
+		return "", err
+	}
+
+	key := sha256.New()
+	for _, part := range []string{
+		hex.EncodeToString(audioHash.Sum(nil)),
+		string(cfg.Provider),
+		cfg.Model,
+		cfg.Language,
+		cfg.Prompt,
+		string(cfg.Task),
+		string(cfg.AudioProfile),
+		audio.FilterChain(cfg.AudioFilters, cfg.AudioFilterRaw),
+		strconv.FormatFloat(cfg.Speed, 'f', -1, 64),
+		strconv.FormatBool(cfg.Outputs.Enabled(domain.ArtifactDiarized)),
+		strconv.FormatBool(cfg.Outputs.Enabled(domain.ArtifactWords)),
+		strconv.FormatBool(cfg.Outputs.Enabled(domain.ArtifactRaw)),
+	} {
+		_, _ = io.WriteString(key, part)
+		_, _ = key.Write([]byte{0})
+	}
+	return hex.EncodeToString(key.Sum(nil)), nil
+}
+
+func checkpointPath(workDir string, key string) string {
+	return filepath.Join(workDir, checkpointDir, key+".json")
+}
+
+// loadCheckpoint returns a stored result; unreadable or truncated
+// checkpoints, e.g. from an interrupted write, count as missing.
+func (a *Application) loadCheckpoint(workDir string, key string) (provider.Response, bool) {
+	data, err := a.FS.ReadFile(checkpointPath(workDir, key))
+	if err != nil {
+		return provider.Response{}, false
+	}
+	var stored cache.Entry
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return provider.Response{}, false
+	}
+	return provider.Response{Transcript: stored.Transcript, Raw: stored.Raw}, true
+}
+
+func (a *Application) saveCheckpoint(workDir string, key string, response provider.Response) error {
+	if err := a.FS.MkdirAll(filepath.Join(workDir, checkpointDir), 0o755); err != nil {
+		return fmt.Errorf("create checkpoint directory: %w", err)
+	}
+	data, err := json.Marshal(cache.Entry{Transcript: response.Transcript, Raw: response.Raw})
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+	if err := a.FS.WriteFile(checkpointPath(workDir, key), data, 0o644); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// CacheStore returns the shared transcription cache in dir.
+func (a *Application) CacheStore(dir string) cache.Store {
+	return cache.Store{FS: a.FS, Dir: dir}
+}
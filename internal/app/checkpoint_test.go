@@ -0,0 +1,197 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+	"github.com/arykalin/whisper-cli/internal/provider"
+	"github.com/rs/zerolog"
+)
+
+type countingProvider struct {
+	fakeProvider
+	mu    sync.Mutex
+	calls []string
+}
+
+func (p *countingProvider) Transcribe(ctx context.Context, req provider.Request) (provider.Response, error) {
+	p.mu.Lock()
+	p.calls = append(p.calls, filepath.Base(req.FilePath))
+	p.mu.Unlock()
+	return p.fakeProvider.Transcribe(ctx, req)
+}
+
+// orderedProvider lets failing requests return only after the successful
+// ones, so fail-fast cancellation cannot cut a successful chunk short.
+type orderedProvider struct {
+	countingProvider
+	succeeded chan struct{}
+}
+
+func (p *orderedProvider) Transcribe(ctx context.Context, req provider.Request) (provider.Response, error) {
+	response, err := p.countingProvider.Transcribe(ctx, req)
+	if err == nil {
+		select {
+		case p.succeeded <- struct{}{}:
+		default:
+		}
+		return response, nil
+	}
+	select {
+	case <-p.succeeded:
+	case <-ctx.Done():
+	}
+	return response, err
+}
+
+func TestApplicationRunResumesFromChunkCheckpoints(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	outputRoot := filepath.Join(dir, "out")
+	workDir := filepath.Join(outputRoot, "input", "_work")
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		t.Fatalf("mkdir work dir: %v", err)
+	}
+	var chunks []audio.Chunk
+	for idx, name := range []string{"chunk_000.m4a", "chunk_001.m4a"} {
+		path := filepath.Join(workDir, name)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		chunks = append(chunks, audio.Chunk{Number: idx, Path: path, Offset: float64(idx) * 600})
+	}
+
+	client := &orderedProvider{
+		countingProvider: countingProvider{fakeProvider: fakeProvider{
+			name:         domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{"whisper-1": {}},
+			responses: map[string]provider.Response{
+				chunks[0].Path: {Transcript: domain.Transcript{Text: "first"}},
+			},
+		}},
+		succeeded: make(chan struct{}, len(chunks)),
+	}
+	app := &Application{
+		FS:       fsx.OS{},
+		Audio:    &fakeAudioPipeline{chunks: chunks},
+		Registry: provider.NewRegistry(client),
+		Logger:   zerolog.New(io.Discard),
+		Env:      staticEnv{},
+	}
+	cfg := config.Config{
+		Input:        input,
+		OutputDir:    outputRoot,
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Language:     "ru",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 600,
+		Concurrency:  2,
+	}
+
+	if err := app.Run(context.Background(), cfg); err == nil {
+		t.Fatalf("expected the first run to fail on chunk 1")
+	}
+
+	client.responses[chunks[1].Path] = provider.Response{Transcript: domain.Transcript{Text: "second"}}
+	client.calls = nil
+	if err := app.Run(context.Background(), cfg); err != nil {
+		t.Fatalf("resumed Run returned error: %v", err)
+	}
+	if !slices.Equal(client.calls, []string{"chunk_001.m4a"}) {
+		t.Fatalf("resumed provider calls = %v, want only chunk_001.m4a", client.calls)
+	}
+	text, err := os.ReadFile(filepath.Join(outputRoot, "input", "transcript.txt"))
+	if err != nil {
+		t.Fatalf("read transcript.txt: %v", err)
+	}
+	if string(text) != "first\nsecond\n" && string(text) != "first\nsecond" {
+		t.Fatalf("transcript.txt = %q", string(text))
+	}
+
+	client.calls = nil
+	cfg.Language = "en"
+	if err := app.Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run with another language returned error: %v", err)
+	}
+	if len(client.calls) != 2 {
+		t.Fatalf("provider calls after language change = %v, want both chunks", client.calls)
+	}
+
+	client.calls = nil
+	cfg.Fresh = true
+	if err := app.Run(context.Background(), cfg); err != nil {
+		t.Fatalf("fresh Run returned error: %v", err)
+	}
+	if len(client.calls) != 2 {
+		t.Fatalf("fresh provider calls = %v, want both chunks", client.calls)
+	}
+}
+
+func TestApplicationRunReusesSharedCacheAcrossOutputDirs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	chunkPath := filepath.Join(dir, "chunk_000.m4a")
+	if err := os.WriteFile(chunkPath, []byte("audio"), 0o644); err != nil {
+		t.Fatalf("write chunk: %v", err)
+	}
+	client := &countingProvider{fakeProvider: fakeProvider{
+		name:         domain.ProviderOpenAI,
+		capabilities: map[string]domain.Capabilities{"whisper-1": {}},
+		responses: map[string]provider.Response{
+			chunkPath: {Transcript: domain.Transcript{Text: "hello"}},
+		},
+	}}
+	app := &Application{
+		FS:       fsx.OS{},
+		Audio:    &fakeAudioPipeline{chunks: []audio.Chunk{{Number: 0, Path: chunkPath}}},
+		Registry: provider.NewRegistry(client),
+		Logger:   zerolog.New(io.Discard),
+		Env:      staticEnv{},
+	}
+
+	for idx, name := range []string{"meeting.m4a", "meeting-renamed.m4a"} {
+		input := filepath.Join(dir, name)
+		if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write input: %v", err)
+		}
+		err := app.Run(context.Background(), config.Config{
+			Input:        input,
+			OutputDir:    filepath.Join(dir, "out", strconv.Itoa(idx)),
+			Provider:     domain.ProviderOpenAI,
+			Model:        "whisper-1",
+			Outputs:      domain.ArtifactSet{},
+			ChunkSeconds: 600,
+			Concurrency:  1,
+			Cache:        true,
+			CacheDir:     filepath.Join(dir, "cache"),
+		})
+		if err != nil {
+			t.Fatalf("Run %d returned error: %v", idx, err)
+		}
+	}
+
+	if len(client.calls) != 1 {
+		t.Fatalf("provider calls = %v, want the second run served from cache", client.calls)
+	}
+	stats, err := app.CacheStore(filepath.Join(dir, "cache")).Stats()
+	if err != nil || stats.Entries != 1 {
+		t.Fatalf("cache stats = %#v, %v", stats, err)
+	}
+}
@@ -0,0 +1,87 @@
+//go:build linux
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyPollMillis bounds how long a read waits before checking for
+// shutdown.
+const inotifyPollMillis = 200
+
+// watchDir reports files in dir that were closed after writing or moved
+// into it. The channel is closed when ctx is done or the watch fails.
+func watchDir(ctx context.Context, dir string) (<-chan string, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("init inotify: %w", err)
+	}
+	if _, err := unix.InotifyAddWatch(fd, dir, unix.IN_CLOSE_WRITE|unix.IN_MOVED_TO); err != nil {
+		_ = unix.Close(fd)
+		return nil, fmt.Errorf("watch %s: %w", dir, err)
+	}
+
+	events := make(chan string)
+	go func() {
+		defer close(events)
+		defer unix.Close(fd)
+
+		buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+		for ctx.Err() == nil {
+			fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+			ready, err := unix.Poll(fds, inotifyPollMillis)
+			if errors.Is(err, unix.EINTR) || (err == nil && ready == 0) {
+				continue
+			}
+			if err != nil {
+				return
+			}
+
+			n, err := unix.Read(fd, buf)
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			if err != nil || n <= 0 {
+				return
+			}
+			for _, name := range parseInotifyEvents(buf[:n]) {
+				select {
+				case events <- filepath.Join(dir, name):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// parseInotifyEvents returns the file names of the events in buf, skipping
+// events about directories.
+func parseInotifyEvents(buf []byte) []string {
+	var names []string
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buf); {
+		event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		nameStart := offset + unix.SizeofInotifyEvent
+		nameEnd := nameStart + int(event.Len)
+		if nameEnd > len(buf) {
+			break
+		}
+		if event.Mask&unix.IN_ISDIR == 0 && event.Len > 0 {
+			name := buf[nameStart:nameEnd]
+			for len(name) > 0 && name[len(name)-1] == 0 {
+				name = name[:len(name)-1]
+			}
+			names = append(names, string(name))
+		}
+		offset = nameEnd
+	}
+	return names
+}
@@ -0,0 +1,52 @@
+//go:build linux
+
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchDirReportsClosedAndMovedFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := watchDir(ctx, dir)
+	if err != nil {
+		t.Fatalf("watchDir returned error: %v", err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.mp3"), []byte("a"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	staged := filepath.Join(t.TempDir(), "b.mp3")
+	if err := os.WriteFile(staged, []byte("b"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Rename(staged, filepath.Join(dir, "b.mp3")); err != nil {
+		t.Skipf("rename across temp dirs not possible: %v", err)
+	}
+
+	for _, want := range []string{"a.mp3", "b.mp3"} {
+		select {
+		case got := <-events:
+			if got != filepath.Join(dir, want) {
+				t.Fatalf("event = %s, want %s", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no event for %s", want)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package app
+
+import (
+	"context"
+	"errors"
+)
+
+func watchDir(context.Context, string) (<-chan string, error) {
+	return nil, errors.New("watch mode relies on inotify and is only supported on Linux")
+}
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stdinInput is the --input and --input-list value that reads from stdin.
+const stdinInput = "-"
+
+// stdinOutputName names the artifact directory of media read from stdin.
+const stdinOutputName = "stdin"
+
+// spoolStdin copies media piped on stdin into workDir, because ffprobe and
+// the chunking passes need a seekable file they can read more than once.
+func (a *Application) spoolStdin(workDir string) (string, error) {
+	if a.Stdin == nil {
+		return "", errors.New("stdin is not available")
+	}
+	if err := a.FS.MkdirAll(workDir, 0o755); err != nil {
+		return "", fmt.Errorf("create work directory: %w", err)
+	}
+
+	path := filepath.Join(workDir, stdinOutputName)
+	file, err := a.FS.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("create stdin spool file: %w", err)
+	}
+	written, err := io.Copy(file, a.Stdin)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("spool stdin: %w", err)
+	}
+	if written == 0 {
+		return "", errors.New("stdin is empty; pipe media into --input -")
+	}
+
+	a.Logger.Info().
+		Str("spool", path).
+		Int64("bytes", written).
+		Msg("spooled media from stdin")
+	return path, nil
+}
+
+// readInputList loads an --input-list and resolves its entries to absolute
+// file paths. Entries are separated by newlines, or by NUL when the list
+// contains one, so `find -print0` output is accepted as is. Glob entries
+// are expanded; directories and duplicates are skipped.
+func (a *Application) readInputList(listPath string) ([]string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if listPath == stdinInput {
+		if a.Stdin == nil {
+			return nil, errors.New("stdin is not available")
+		}
+		data, err = io.ReadAll(a.Stdin)
+	} else {
+		data, err = a.FS.ReadFile(listPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read input list: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, entry := range parseInputList(data) {
+		path, err := a.FS.Abs(filepath.Clean(entry))
+		if err != nil {
+			return nil, fmt.Errorf("resolve input list entry %s: %w", entry, err)
+		}
+
+		matches := []string{path}
+		if hasGlobMeta(entry) {
+			matches, err = filepath.Glob(path)
+			if err != nil {
+				return nil, fmt.Errorf("input list entry %s: %w", entry, err)
+			}
+			if len(matches) == 0 {
+				a.Logger.Warn().Str("pattern", entry).Msg("input list pattern matched no files")
+				continue
+			}
+		}
+
+		for _, match := range matches {
+			info, err := a.FS.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("stat input list entry %s: %w", match, err)
+			}
+			if info.IsDir() {
+				a.Logger.Warn().Str("path", match).Msg("skipping directory in input list")
+				continue
+			}
+			if seen[match] {
+				continue
+			}
+			seen[match] = true
+			files = append(files, match)
+		}
+	}
+	return files, nil
+}
+
+func parseInputList(data []byte) []string {
+	sep := []byte("\n")
+	if bytes.IndexByte(data, 0) >= 0 {
+		sep = []byte{0}
+	}
+
+	var entries []string
+	for _, raw := range bytes.Split(data, sep) {
+		entry := strings.TrimSuffix(string(raw), "\r")
+		if sep[0] == '\n' {
+			entry = strings.TrimSpace(entry)
+		}
+		if entry == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// commonDir returns the deepest directory containing every file, so list
+// input can reuse the mirrored output layout of directory input.
+func commonDir(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	common := filepath.Dir(files[0])
+	for _, file := range files[1:] {
+		for !withinDir(common, file) {
+			parent := filepath.Dir(common)
+			if parent == common {
+				break
+			}
+			common = parent
+		}
+	}
+	return common
+}
+
+func withinDir(dir string, file string) bool {
+	rel, err := filepath.Rel(dir, file)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
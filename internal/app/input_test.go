@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+	"github.com/arykalin/whisper-cli/internal/provider"
+	"github.com/rs/zerolog"
+)
+
+func TestApplicationRunSpoolsStdinIntoWorkDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	outputRoot := filepath.Join(dir, "out")
+	audioPipeline := &fakeAudioPipeline{
+		chunks: []audio.Chunk{{Number: 0, Path: "chunk-0", Offset: 0}},
+	}
+	app := &Application{
+		FS:    fsx.OS{},
+		Audio: audioPipeline,
+		Registry: provider.NewRegistry(fakeProvider{
+			name:         domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{"whisper-1": {}},
+			responses: map[string]provider.Response{
+				"chunk-0": {Transcript: domain.Transcript{Text: "hello"}},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+		Stdin:  strings.NewReader("media bytes"),
+	}
+
+	err := app.Run(context.Background(), config.Config{
+		Input:        "-",
+		OutputDir:    outputRoot,
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 600,
+		Concurrency:  1,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	spool := filepath.Join(outputRoot, "stdin", "_work", "stdin")
+	if got := audioPipeline.prepareInputCalls[0].inputFile; got != spool {
+		t.Fatalf("PrepareInput input = %s, want %s", got, spool)
+	}
+	data, err := os.ReadFile(spool)
+	if err != nil {
+		t.Fatalf("read spool: %v", err)
+	}
+	if string(data) != "media bytes" {
+		t.Fatalf("spool = %q", string(data))
+	}
+	if _, err := os.Stat(filepath.Join(outputRoot, "stdin", "transcript.txt")); err != nil {
+		t.Fatalf("expected transcript.txt for stdin input: %v", err)
+	}
+}
+
+func TestReadInputListExpandsGlobsAndNULSeparatedEntries(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.mp3", "b.mp3", "c d.wav", "sub/e.m4a"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	app := &Application{
+		FS:     fsx.OS{},
+		Logger: zerolog.New(io.Discard),
+		Stdin: strings.NewReader(strings.Join([]string{
+			filepath.Join(dir, "*.mp3"),
+			filepath.Join(dir, "c d.wav"),
+			filepath.Join(dir, "a.mp3"),
+			filepath.Join(dir, "sub"),
+			filepath.Join(dir, "sub", "e.m4a"),
+		}, "\x00") + "\x00"),
+	}
+
+	files, err := app.readInputList("-")
+	if err != nil {
+		t.Fatalf("readInputList returned error: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "a.mp3"),
+		filepath.Join(dir, "b.mp3"),
+		filepath.Join(dir, "c d.wav"),
+		filepath.Join(dir, "sub", "e.m4a"),
+	}
+	if !slices.Equal(files, want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+	if got := commonDir(files); got != dir {
+		t.Fatalf("commonDir = %s, want %s", got, dir)
+	}
+}
+
+func TestParseInputListSplitsLines(t *testing.T) {
+	t.Parallel()
+
+	entries := parseInputList([]byte("one.mp3\r\n\n  two.wav  \n"))
+	if !slices.Equal(entries, []string{"one.mp3", "two.wav"}) {
+		t.Fatalf("entries = %q", entries)
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// mirrorOutputDirs maps every collected file to its artifact directory. The
+// relative source tree is mirrored under outputRoot; files in one directory
+// that share a name stem keep their extension in the directory name, so
+// lecture.mp3 and lecture.m4a do not overwrite each other.
+func mirrorOutputDirs(inputRoot string, outputRoot string, files []string) map[string]string {
+	stemKey := func(file string) string {
+		return strings.ToLower(strings.TrimSuffix(file, filepath.Ext(file)))
+	}
+
+	stems := make(map[string]int, len(files))
+	for _, file := range files {
+		stems[stemKey(file)]++
+	}
+
+	dirs := make(map[string]string, len(files))
+	for _, file := range files {
+		rel, err := filepath.Rel(inputRoot, file)
+		if err != nil {
+			rel = filepath.Base(file)
+		}
+		ext := filepath.Ext(rel)
+		name := strings.TrimSuffix(rel, ext)
+		if stems[stemKey(file)] > 1 && ext != "" {
+			name += "_" + strings.TrimPrefix(ext, ".")
+		}
+		dirs[file] = filepath.Join(outputRoot, name)
+	}
+	return dirs
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMirrorOutputDirsKeepsTreeAndSeparatesSharedStems(t *testing.T) {
+	t.Parallel()
+
+	input := filepath.Join("/media", "course")
+	output := filepath.Join("/out")
+	files := []string{
+		filepath.Join(input, "lecture.m4a"),
+		filepath.Join(input, "lecture.mp3"),
+		filepath.Join(input, "week1", "lecture.mp3"),
+		filepath.Join(input, "week2", "intro.wav"),
+	}
+
+	dirs := mirrorOutputDirs(input, output, files)
+
+	want := map[string]string{
+		files[0]: filepath.Join(output, "lecture_m4a"),
+		files[1]: filepath.Join(output, "lecture_mp3"),
+		files[2]: filepath.Join(output, "week1", "lecture"),
+		files[3]: filepath.Join(output, "week2", "intro"),
+	}
+	for file, dir := range want {
+		if dirs[file] != dir {
+			t.Fatalf("output dir for %s = %s, want %s", file, dirs[file], dir)
+		}
+	}
+}
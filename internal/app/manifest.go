@@ -0,0 +1,253 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+)
+
+// manifestName is the run manifest at the output root. It records what
+// every input produced, so --incremental reruns can skip unchanged files.
+const manifestName = "manifest.json"
+
+const manifestVersion = 1
+
+type manifest struct {
+	Version int                      `json:"version"`
+	Files   map[string]manifestEntry `json:"files"`
+}
+
+type manifestEntry struct {
+	Size        int64              `json:"size"`
+	ModTime     time.Time          `json:"mod_time"`
+	SHA256      string             `json:"sha256"`
+	OutputDir   string             `json:"output_dir"`
+	Settings    manifestSettings   `json:"settings"`
+	Outputs     []string           `json:"outputs"`
+	Artifacts   []manifestArtifact `json:"artifacts"`
+	CompletedAt time.Time          `json:"completed_at"`
+}
+
+// manifestSettings is the effective configuration that shapes a transcript.
+// Paths, concurrency and cache settings are left out: they do not change
+// the result.
+type manifestSettings struct {
+	Task             string            `json:"task"`
+	Provider         string            `json:"provider"`
+	Model            string            `json:"model"`
+	Language         string            `json:"language"`
+	Prompt           string            `json:"prompt,omitempty"`
+	ChunkSeconds     int               `json:"chunk_seconds"`
+	ChunkMode        string            `json:"chunk_mode"`
+	SilenceTolerance int               `json:"silence_tolerance"`
+	ChunkOverlap     int               `json:"chunk_overlap"`
+	AudioProfile     string            `json:"audio_profile"`
+	RemoveSilence    int               `json:"remove_silence,omitempty"`
+	Speed            float64           `json:"speed"`
+	AudioFilter      string            `json:"audio_filter,omitempty"`
+	AudioTrack       string            `json:"audio_track,omitempty"`
+	ChannelLabels    []string          `json:"channel_labels,omitempty"`
+	Ranges           []audio.TimeRange `json:"ranges,omitempty"`
+}
+
+type manifestArtifact struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+func settingsFromConfig(cfg config.Config) manifestSettings {
+	return manifestSettings{
+		Task:             string(cfg.Task),
+		Provider:         string(cfg.Provider),
+		Model:            cfg.Model,
+		Language:         cfg.Language,
+		Prompt:           cfg.Prompt,
+		ChunkSeconds:     cfg.ChunkSeconds,
+		ChunkMode:        string(cfg.ChunkMode),
+		SilenceTolerance: cfg.SilenceTolerance,
+		ChunkOverlap:     cfg.ChunkOverlap,
+		AudioProfile:     string(cfg.AudioProfile),
+		RemoveSilence:    cfg.RemoveSilence,
+		Speed:            cfg.Speed,
+		AudioFilter:      audio.FilterChain(cfg.AudioFilters, cfg.AudioFilterRaw),
+		AudioTrack:       cfg.AudioTrack,
+		ChannelLabels:    cfg.ChannelLabels,
+		Ranges:           cfg.Ranges,
+	}
+}
+
+func outputNames(cfg config.Config) []string {
+	names := make([]string, 0, len(cfg.Outputs))
+	for artifact, enabled := range cfg.Outputs {
+		if enabled {
+			names = append(names, string(artifact))
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// runManifest is the manifest of one output root, shared by the files of a
+// batch. It is saved after every completed file, so an interrupted batch
+// keeps what it finished.
+type runManifest struct {
+	fs   fsx.FS
+	path string
+
+	mu   sync.Mutex
+	data manifest
+}
+
+func loadManifest(fs fsx.FS, outputRoot string) (*runManifest, error) {
+	m := &runManifest{
+		fs:   fs,
+		path: filepath.Join(outputRoot, manifestName),
+		data: manifest{Version: manifestVersion, Files: map[string]manifestEntry{}},
+	}
+
+	data, err := fs.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.data); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", m.path, err)
+	}
+	if m.data.Files == nil {
+		m.data.Files = map[string]manifestEntry{}
+	}
+	return m, nil
+}
+
+// upToDate reports whether file was transcribed before from the same
+// content, with the same settings and outputs, and its artifacts are still
+// intact. Content is rehashed only when size or mtime changed.
+func (m *runManifest) upToDate(file string, cfg config.Config, outputDir string) (bool, error) {
+	m.mu.Lock()
+	entry, ok := m.data.Files[file]
+	m.mu.Unlock()
+	if !ok || entry.OutputDir != outputDir {
+		return false, nil
+	}
+
+	want, err := json.Marshal(settingsFromConfig(cfg))
+	if err != nil {
+		return false, err
+	}
+	got, err := json.Marshal(entry.Settings)
+	if err != nil {
+		return false, err
+	}
+	if string(want) != string(got) || !slices.Equal(entry.Outputs, outputNames(cfg)) {
+		return false, nil
+	}
+
+	info, err := m.fs.Stat(file)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != entry.Size || !info.ModTime().Equal(entry.ModTime) {
+		sum, err := fileSHA256(m.fs, file)
+		if err != nil {
+			return false, err
+		}
+		if sum != entry.SHA256 {
+			return false, nil
+		}
+	}
+
+	for _, artifact := range entry.Artifacts {
+		sum, err := fileSHA256(m.fs, filepath.Join(outputDir, artifact.Name))
+		if err != nil || sum != artifact.SHA256 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// record stores the completed transcription of file and saves the manifest.
+func (m *runManifest) record(file string, cfg config.Config, outputDir string) error {
+	info, err := m.fs.Stat(file)
+	if err != nil {
+		return err
+	}
+	sum, err := fileSHA256(m.fs, file)
+	if err != nil {
+		return err
+	}
+	artifacts, err := listArtifacts(m.fs, outputDir)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data.Files[file] = manifestEntry{
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		SHA256:      sum,
+		OutputDir:   outputDir,
+		Settings:    settingsFromConfig(cfg),
+		Outputs:     outputNames(cfg),
+		Artifacts:   artifacts,
+		CompletedAt: time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(m.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	if err := m.fs.WriteFile(m.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return nil
+}
+
+// listArtifacts checksums the files written to outputDir; _work is skipped.
+func listArtifacts(fs fsx.FS, outputDir string) ([]manifestArtifact, error) {
+	entries, err := fs.ReadDir(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("list artifacts: %w", err)
+	}
+
+	var artifacts []manifestArtifact
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		sum, err := fileSHA256(fs, filepath.Join(outputDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, manifestArtifact{Name: entry.Name(), SHA256: sum})
+	}
+	return artifacts, nil
+}
+
+func fileSHA256(fs fsx.FS, path string) (string, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+	"github.com/arykalin/whisper-cli/internal/provider"
+	"github.com/rs/zerolog"
+)
+
+func TestApplicationRunIncrementalSkipsUnchangedFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "in")
+	if err := os.MkdirAll(inputDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	var files []string
+	sourceChunks := map[string][]audio.Chunk{}
+	responses := map[string]provider.Response{}
+	for _, name := range []string{"a.mp3", "b.mp3"} {
+		file := filepath.Join(inputDir, name)
+		if err := os.WriteFile(file, []byte(name), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		files = append(files, file)
+		sourceChunks[file] = []audio.Chunk{{Number: 0, Path: name + "-chunk"}}
+		responses[name+"-chunk"] = provider.Response{Transcript: domain.Transcript{
+			Text:     name,
+			Segments: []domain.Segment{{Start: 0, End: 1, Text: name}},
+		}}
+	}
+
+	client := &countingProvider{fakeProvider: fakeProvider{
+		name:         domain.ProviderOpenAI,
+		capabilities: map[string]domain.Capabilities{"whisper-1": {SupportsSegmentTimestamps: true}},
+		responses:    responses,
+	}}
+	app := &Application{
+		FS:       fsx.OS{},
+		Audio:    &fakeAudioPipeline{mediaFiles: files, sourceChunks: sourceChunks},
+		Registry: provider.NewRegistry(client),
+		Logger:   zerolog.New(io.Discard),
+		Env:      staticEnv{},
+	}
+	cfg := config.Config{
+		Input:        inputDir,
+		OutputDir:    filepath.Join(dir, "out"),
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 600,
+		Concurrency:  1,
+		Incremental:  true,
+	}
+	run := func() []string {
+		t.Helper()
+		client.calls = nil
+		if err := app.Run(context.Background(), cfg); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		calls := slices.Clone(client.calls)
+		slices.Sort(calls)
+		return calls
+	}
+
+	if calls := run(); len(calls) != 2 {
+		t.Fatalf("first run calls = %v, want both files", calls)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "out", "manifest.json"))
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var written manifest
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	entry := written.Files[files[0]]
+	if entry.SHA256 == "" || entry.Settings.Model != "whisper-1" || len(entry.Artifacts) == 0 {
+		t.Fatalf("manifest entry = %#v", entry)
+	}
+
+	if calls := run(); len(calls) != 0 {
+		t.Fatalf("unchanged rerun calls = %v, want none", calls)
+	}
+
+	if err := os.WriteFile(files[1], []byte("b.mp3 re-recorded"), 0o644); err != nil {
+		t.Fatalf("rewrite input: %v", err)
+	}
+	if calls := run(); !slices.Equal(calls, []string{"b.mp3-chunk"}) {
+		t.Fatalf("calls after changing b.mp3 = %v", calls)
+	}
+
+	cfg.Outputs = domain.ArtifactSet{domain.ArtifactTimestamps: true}
+	if calls := run(); len(calls) != 2 {
+		t.Fatalf("calls after changing outputs = %v, want both files", calls)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "out", "a", "timestamps.txt")); err != nil {
+		t.Fatalf("remove artifact: %v", err)
+	}
+	if calls := run(); !slices.Equal(calls, []string{"a.mp3-chunk"}) {
+		t.Fatalf("calls after deleting an artifact = %v", calls)
+	}
+}
@@ -0,0 +1,40 @@
+package app
+
+import "context"
+
+// workerPool bounds the work of one run. Every provider request, whichever
+// file its chunk belongs to, takes a chunk slot, and every ffmpeg pass takes
+// an ffmpeg slot, so files can be preprocessed while earlier ones upload.
+type workerPool struct {
+	chunks semaphore
+	ffmpeg semaphore
+}
+
+func newWorkerPool(chunkWorkers int, ffmpegJobs int) *workerPool {
+	return &workerPool{
+		chunks: newSemaphore(chunkWorkers),
+		ffmpeg: newSemaphore(ffmpegJobs),
+	}
+}
+
+type semaphore chan struct{}
+
+func newSemaphore(size int) semaphore {
+	if size <= 0 {
+		size = 1
+	}
+	return make(semaphore, size)
+}
+
+func (s semaphore) acquire(ctx context.Context) error {
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	<-s
+}
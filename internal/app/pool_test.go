@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+	"github.com/arykalin/whisper-cli/internal/provider"
+	"github.com/rs/zerolog"
+)
+
+// overlapProvider holds every request until two are in flight, so it only
+// completes when requests from different files run at the same time.
+type overlapProvider struct {
+	fakeProvider
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	both        chan struct{}
+}
+
+func (p *overlapProvider) Transcribe(ctx context.Context, req provider.Request) (provider.Response, error) {
+	p.mu.Lock()
+	p.inFlight++
+	p.maxInFlight = max(p.maxInFlight, p.inFlight)
+	if p.inFlight == 2 {
+		select {
+		case <-p.both:
+		default:
+			close(p.both)
+		}
+	}
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.inFlight--
+		p.mu.Unlock()
+	}()
+
+	select {
+	case <-p.both:
+	case <-time.After(5 * time.Second):
+		return provider.Response{}, errors.New("requests from different files never overlapped")
+	}
+	return p.fakeProvider.Transcribe(ctx, req)
+}
+
+func TestApplicationRunSharesChunkPoolAcrossFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	names := []string{"a.mp3", "b.mp3", "c.mp3"}
+	files := make([]string, 0, len(names))
+	sourceChunks := make(map[string][]audio.Chunk, len(names))
+	responses := make(map[string]provider.Response, len(names))
+	for _, name := range names {
+		file := filepath.Join(dir, "in", name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		files = append(files, file)
+		sourceChunks[file] = []audio.Chunk{{Number: 0, Path: name + "-chunk-0"}}
+		responses[name+"-chunk-0"] = provider.Response{Transcript: domain.Transcript{Text: name}}
+	}
+
+	client := &overlapProvider{
+		fakeProvider: fakeProvider{
+			name:         domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{"whisper-1": {}},
+			responses:    responses,
+		},
+		both: make(chan struct{}),
+	}
+	app := &Application{
+		FS:       fsx.OS{},
+		Audio:    &fakeAudioPipeline{mediaFiles: files, sourceChunks: sourceChunks},
+		Registry: provider.NewRegistry(client),
+		Logger:   zerolog.New(io.Discard),
+		Env:      staticEnv{},
+	}
+
+	err := app.Run(context.Background(), config.Config{
+		Input:        filepath.Join(dir, "in"),
+		OutputDir:    filepath.Join(dir, "out"),
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 600,
+		Concurrency:  2,
+		FFmpegJobs:   1,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if client.maxInFlight != 2 {
+		t.Fatalf("max in-flight requests = %d, want 2", client.maxInFlight)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := os.Stat(filepath.Join(dir, "out", name, "transcript.txt")); err != nil {
+			t.Fatalf("expected transcript for %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+	"github.com/arykalin/whisper-cli/internal/provider"
+	"github.com/arykalin/whisper-cli/internal/provider/compatadapter"
+	"github.com/rs/zerolog"
+)
+
+const (
+	providersFileEnv = "WHISPER_CLI_PROVIDERS_FILE"
+	providersEnv     = "WHISPER_CLI_PROVIDERS"
+)
+
+// compatibleClients builds clients for user-declared OpenAI-compatible
+// endpoints from the definition file and inline env definitions.
+func compatibleClients(filesystem fsx.FS, env config.EnvSource, logger zerolog.Logger) ([]provider.Client, error) {
+	type document struct {
+		source string
+		data   []byte
+	}
+	var documents []document
+	if path, ok := env.LookupEnv(providersFileEnv); ok && strings.TrimSpace(path) != "" {
+		data, err := filesystem.ReadFile(strings.TrimSpace(path))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", providersFileEnv, err)
+		}
+		documents = append(documents, document{source: providersFileEnv, data: data})
+	}
+	if inline, ok := env.LookupEnv(providersEnv); ok && strings.TrimSpace(inline) != "" {
+		documents = append(documents, document{source: providersEnv, data: []byte(inline)})
+	}
+
+	var (
+		clients []provider.Client
+		seen    = map[string]struct{}{}
+	)
+	for _, doc := range documents {
+		defs, err := compatadapter.ParseDefinitions(doc.data)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", doc.source, err)
+		}
+		for _, def := range defs {
+			if _, ok := seen[def.Name]; ok {
+				return nil, fmt.Errorf("provider %s is declared more than once", def.Name)
+			}
+			seen[def.Name] = struct{}{}
+
+			var apiKey string
+			if def.APIKeyEnv != "" {
+				apiKey, _ = env.LookupEnv(def.APIKeyEnv)
+			}
+			clients = append(clients, compatadapter.New(def, strings.TrimSpace(apiKey), filesystem, logger))
+		}
+	}
+	return clients, nil
+}
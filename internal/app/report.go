@@ -0,0 +1,229 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/provider"
+)
+
+const (
+	reportFile    = "report.json"
+	reportVersion = 1
+)
+
+// ErrorClass groups failures by what the operator has to do about them.
+type ErrorClass string
+
+const (
+	ErrorClassConfig        ErrorClass = "config"
+	ErrorClassMissingFFmpeg ErrorClass = "missing_ffmpeg"
+	ErrorClassAuth          ErrorClass = "auth"
+	ErrorClassQuota         ErrorClass = "quota"
+	// ErrorClassPartial marks a run where some files failed or have gaps
+	// while the rest were transcribed.
+	ErrorClassPartial  ErrorClass = "partial"
+	ErrorClassCanceled ErrorClass = "canceled"
+	ErrorClassFailed   ErrorClass = "failed"
+)
+
+// ClassError attaches an ErrorClass to an error that cannot be classified
+// by its content, such as a rejected setting.
+type ClassError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *ClassError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError returns the class of err; provider errors are told apart by
+// their HTTP status.
+func ClassifyError(err error) ErrorClass {
+	var classified *ClassError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &classified):
+		return classified.Class
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case provider.IsAuthError(err):
+		return ErrorClassAuth
+	case provider.IsQuotaError(err):
+		return ErrorClassQuota
+	default:
+		return ErrorClassFailed
+	}
+}
+
+type fileStatus string
+
+const (
+	fileOK      fileStatus = "ok"
+	filePartial fileStatus = "partial"
+	fileFailed  fileStatus = "failed"
+	fileSkipped fileStatus = "skipped"
+)
+
+// fileReport is the outcome of one input in report.json.
+type fileReport struct {
+	Input           string          `json:"input"`
+	OutputDir       string          `json:"output_dir"`
+	Status          fileStatus      `json:"status"`
+	Provider        domain.Provider `json:"provider"`
+	Model           string          `json:"model"`
+	DurationSeconds float64         `json:"duration_seconds"`
+	Chunks          int             `json:"chunks"`
+	FailedChunks    int             `json:"failed_chunks,omitempty"`
+	ElapsedSeconds  float64         `json:"elapsed_seconds"`
+	Retries         int64           `json:"retries"`
+	Error           string          `json:"error,omitempty"`
+	ErrorClass      ErrorClass      `json:"error_class,omitempty"`
+
+	err error
+}
+
+func newFileReport(cfg config.Config, file string, outputDir string) fileReport {
+	return fileReport{
+		Input:     file,
+		OutputDir: outputDir,
+		Provider:  cfg.Provider,
+		Model:     cfg.Model,
+	}
+}
+
+func (r *fileReport) fail(err error) {
+	r.Status = fileFailed
+	r.Error = err.Error()
+	r.ErrorClass = ClassifyError(err)
+	r.err = err
+}
+
+// runReport is report.json: one entry per input plus totals, so scripts
+// do not have to parse logs to find out what a batch run did.
+type runReport struct {
+	Version        int          `json:"version"`
+	StartedAt      time.Time    `json:"started_at"`
+	FinishedAt     time.Time    `json:"finished_at"`
+	ElapsedSeconds float64      `json:"elapsed_seconds"`
+	Provider       string       `json:"provider"`
+	Model          string       `json:"model"`
+	Status         fileStatus   `json:"status"`
+	ErrorClass     ErrorClass   `json:"error_class,omitempty"`
+	Totals         reportTotals `json:"totals"`
+	Files          []fileReport `json:"files"`
+}
+
+type reportTotals struct {
+	Files   int `json:"files"`
+	OK      int `json:"ok"`
+	Partial int `json:"partial"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// finishRun writes report.json into outputRoot and turns the file outcomes
+// into the error of the run.
+func (a *Application) finishRun(cfg config.Config, outputRoot string, started time.Time, files []fileReport) error {
+	finished := time.Now()
+	report := runReport{
+		Version:        reportVersion,
+		StartedAt:      started.UTC(),
+		FinishedAt:     finished.UTC(),
+		ElapsedSeconds: finished.Sub(started).Seconds(),
+		Provider:       string(cfg.Provider),
+		Model:          cfg.Model,
+		Files:          files,
+	}
+	for _, file := range files {
+		report.Totals.Files++
+		switch file.Status {
+		case fileOK:
+			report.Totals.OK++
+		case filePartial:
+			report.Totals.Partial++
+		case fileFailed:
+			report.Totals.Failed++
+		case fileSkipped:
+			report.Totals.Skipped++
+		}
+	}
+
+	err := runError(files, report.Totals)
+	report.ErrorClass = ClassifyError(err)
+	switch {
+	case err == nil:
+		report.Status = fileOK
+	case report.Totals.Failed == report.Totals.Files:
+		report.Status = fileFailed
+	default:
+		report.Status = filePartial
+	}
+
+	data, marshalErr := json.MarshalIndent(report, "", "  ")
+	if marshalErr == nil {
+		marshalErr = a.FS.MkdirAll(outputRoot, 0o755)
+	}
+	if marshalErr == nil {
+		marshalErr = a.FS.WriteFile(filepath.Join(outputRoot, reportFile), append(data, '\n'), 0o644)
+	}
+	if marshalErr != nil {
+		a.Logger.Warn().Err(marshalErr).Str("output_dir", outputRoot).Msg("failed to write run report")
+	}
+
+	a.Logger.Info().
+		Int("files", report.Totals.Files).
+		Int("ok", report.Totals.OK).
+		Int("partial", report.Totals.Partial).
+		Int("failed", report.Totals.Failed).
+		Int("skipped", report.Totals.Skipped).
+		Str("report", filepath.Join(outputRoot, reportFile)).
+		Msg("finished run")
+	return err
+}
+
+// runError returns nil when every file succeeded or was skipped. Otherwise
+// it returns the first failure in input order, classified so that auth and
+// quota problems win over partial success: those need the operator before
+// a rerun can help.
+func runError(files []fileReport, totals reportTotals) error {
+	var first error
+	class := ErrorClass("")
+	for _, file := range files {
+		if file.err == nil {
+			continue
+		}
+		if first == nil {
+			first = file.err
+			class = file.ErrorClass
+		}
+		if file.ErrorClass == ErrorClassAuth || (file.ErrorClass == ErrorClassQuota && class != ErrorClassAuth) {
+			class = file.ErrorClass
+		}
+	}
+
+	switch {
+	case first == nil && totals.Partial == 0:
+		return nil
+	case first == nil:
+		return &ClassError{
+			Class: ErrorClassPartial,
+			Err:   fmt.Errorf("%d of %d files were written with gaps from failed chunks", totals.Partial, totals.Files),
+		}
+	case class != ErrorClassAuth && class != ErrorClassQuota && totals.Failed < totals.Files:
+		class = ErrorClassPartial
+	}
+	return &ClassError{Class: class, Err: first}
+}
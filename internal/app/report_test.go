@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+	"github.com/arykalin/whisper-cli/internal/provider"
+	"github.com/rs/zerolog"
+)
+
+func TestApplicationRunWritesBatchReport(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "in")
+	if err := os.MkdirAll(inputDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	var files []string
+	sourceChunks := map[string][]audio.Chunk{}
+	for _, name := range []string{"a.mp3", "b.mp3"} {
+		file := filepath.Join(inputDir, name)
+		if err := os.WriteFile(file, []byte(name), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		files = append(files, file)
+		sourceChunks[file] = []audio.Chunk{
+			{Number: 0, Path: name + "-0", Duration: 600},
+			{Number: 1, Path: name + "-1", Offset: 600, Duration: 30},
+		}
+	}
+
+	app := &Application{
+		FS:    fsx.OS{},
+		Audio: &fakeAudioPipeline{mediaFiles: files, sourceChunks: sourceChunks},
+		Registry: provider.NewRegistry(fakeProvider{
+			name:         domain.ProviderOpenAI,
+			capabilities: map[string]domain.Capabilities{"whisper-1": {}},
+			responses: map[string]provider.Response{
+				"a.mp3-0": {Transcript: domain.Transcript{Text: "a0"}},
+				"a.mp3-1": {Transcript: domain.Transcript{Text: "a1"}},
+				"b.mp3-0": {Transcript: domain.Transcript{Text: "b0"}},
+			},
+		}),
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+	outputRoot := filepath.Join(dir, "out")
+	err := app.Run(context.Background(), config.Config{
+		Input:        inputDir,
+		OutputDir:    outputRoot,
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 600,
+		Concurrency:  2,
+	})
+	if ClassifyError(err) != ErrorClassPartial {
+		t.Fatalf("expected a partial run error, got %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputRoot, "report.json"))
+	if err != nil {
+		t.Fatalf("read report.json: %v", err)
+	}
+	var report runReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("unmarshal report.json: %v", err)
+	}
+	if report.Status != filePartial || report.ErrorClass != ErrorClassPartial || report.Totals.OK != 1 || report.Totals.Failed != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(report.Files) != 2 {
+		t.Fatalf("files = %+v", report.Files)
+	}
+	ok, failed := report.Files[0], report.Files[1]
+	if ok.Input != files[0] || ok.Status != fileOK || ok.Chunks != 2 || ok.DurationSeconds != 630 || ok.Model != "whisper-1" || ok.Provider != domain.ProviderOpenAI {
+		t.Fatalf("ok entry = %+v", ok)
+	}
+	if failed.Input != files[1] || failed.Status != fileFailed || failed.ErrorClass != ErrorClassFailed || failed.Error == "" {
+		t.Fatalf("failed entry = %+v", failed)
+	}
+}
+
+func TestRunErrorPrefersAuthAndQuotaOverPartial(t *testing.T) {
+	t.Parallel()
+
+	var quota, auth, other fileReport
+	quota.fail(&ClassError{Class: ErrorClassQuota, Err: errors.New("quota")})
+	auth.fail(&ClassError{Class: ErrorClassAuth, Err: errors.New("auth")})
+	other.fail(errors.New("broken file"))
+	done := fileReport{Status: fileOK}
+
+	classOf := func(files ...fileReport) ErrorClass {
+		totals := reportTotals{Files: len(files)}
+		for _, file := range files {
+			if file.Status == fileFailed {
+				totals.Failed++
+			}
+		}
+		return ClassifyError(runError(files, totals))
+	}
+
+	if got := classOf(done, other); got != ErrorClassPartial {
+		t.Fatalf("class with one success = %s, want partial", got)
+	}
+	if got := classOf(done, other, quota); got != ErrorClassQuota {
+		t.Fatalf("class with a quota failure = %s, want quota", got)
+	}
+	if got := classOf(quota, auth, done); got != ErrorClassAuth {
+		t.Fatalf("class with an auth failure = %s, want auth", got)
+	}
+	if got := classOf(other); got != ErrorClassFailed {
+		t.Fatalf("class of a single failure = %s, want failed", got)
+	}
+	if classOf(done) != "" {
+		t.Fatal("expected no error when every file succeeded")
+	}
+}
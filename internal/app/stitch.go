@@ -0,0 +1,133 @@
+package app
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/arykalin/whisper-cli/internal/domain"
+)
+
+const (
+	// maxSeamWords bounds how far the text matcher looks across a chunk seam.
+	maxSeamWords = 60
+	// minSeamWords avoids treating one common word as a duplicated phrase.
+	minSeamWords = 2
+	// seamSimilarity is the share of matching words required for a fuzzy match.
+	seamSimilarity = 0.8
+)
+
+// stitchPiece is one chunk transcript already shifted to the input timeline.
+type stitchPiece struct {
+	offset   float64
+	overlap  float64
+	text     string
+	segments []domain.Segment
+	speakers []domain.SpeakerSegment
+	words    []domain.Word
+}
+
+// stitchOverlaps reconciles neighbouring chunks that share audio. Timed items
+// are split around the middle of the shared region; whatever text is still
+// repeated at the seam is removed from the later chunk by fuzzy word matching.
+func stitchOverlaps(pieces []stitchPiece) {
+	for idx := 1; idx < len(pieces); idx++ {
+		current := &pieces[idx]
+		if current.overlap <= 0 {
+			continue
+		}
+		previous := &pieces[idx-1]
+		cut := current.offset + current.overlap/2
+
+		if len(previous.segments) > 0 || len(current.segments) > 0 {
+			previous.segments, current.segments = splitAtSeam(previous.segments, current.segments, cut, func(s domain.Segment) (float64, float64) { return s.Start, s.End })
+			previous.text = segmentsText(previous.segments)
+			current.text = segmentsText(current.segments)
+		}
+		previous.speakers, current.speakers = splitAtSeam(previous.speakers, current.speakers, cut, func(s domain.SpeakerSegment) (float64, float64) { return s.Start, s.End })
+		previous.words, current.words = splitAtSeam(previous.words, current.words, cut, func(w domain.Word) (float64, float64) { return w.Start, w.End })
+	}
+
+	for idx := 1; idx < len(pieces); idx++ {
+		if pieces[idx].overlap <= 0 {
+			continue
+		}
+		pieces[idx].text = dropRepeatedPrefix(pieces[idx-1].text, pieces[idx].text)
+	}
+}
+
+// splitAtSeam keeps the items of previous whose midpoint lies before cut and
+// the items of current whose midpoint lies after both cut and the end of the
+// last kept item of previous. An item that straddles the cut is kept once,
+// and the later chunk does not repeat the speech it already covers.
+func splitAtSeam[T any](previous []T, current []T, cut float64, span func(T) (float64, float64)) ([]T, []T) {
+	var kept, rest []T
+	boundary := cut
+	for _, item := range previous {
+		if start, end := span(item); midpoint(start, end) < cut {
+			kept = append(kept, item)
+			boundary = max(boundary, end)
+		}
+	}
+	for _, item := range current {
+		if start, end := span(item); midpoint(start, end) >= boundary {
+			rest = append(rest, item)
+		}
+	}
+	return kept, rest
+}
+
+// midpoint treats an item without a usable end as a point at its start.
+func midpoint(start float64, end float64) float64 {
+	if end < start {
+		return start
+	}
+	return (start + end) / 2
+}
+
+func segmentsText(segments []domain.Segment) string {
+	parts := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if text := strings.TrimSpace(segment.Text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// dropRepeatedPrefix removes the longest run of leading words in next that
+// fuzzily repeats the trailing words of previous.
+func dropRepeatedPrefix(previous string, next string) string {
+	tail := strings.Fields(previous)
+	head := strings.Fields(next)
+	if len(tail) > maxSeamWords {
+		tail = tail[len(tail)-maxSeamWords:]
+	}
+
+	limit := min(len(tail), len(head), maxSeamWords)
+	for size := limit; size >= minSeamWords; size-- {
+		if seamMatches(tail[len(tail)-size:], head[:size]) {
+			return strings.Join(head[size:], " ")
+		}
+	}
+	return strings.TrimSpace(next)
+}
+
+func seamMatches(a []string, b []string) bool {
+	matched := 0
+	for idx := range a {
+		if normalizeWord(a[idx]) == normalizeWord(b[idx]) {
+			matched++
+		}
+	}
+	// The outermost words must agree so a fuzzy match cannot eat unrelated words.
+	if normalizeWord(a[0]) != normalizeWord(b[0]) || normalizeWord(a[len(a)-1]) != normalizeWord(b[len(b)-1]) {
+		return false
+	}
+	return float64(matched) >= seamSimilarity*float64(len(a))
+}
+
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	}))
+}
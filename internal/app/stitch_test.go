@@ -0,0 +1,121 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arykalin/whisper-cli/internal/domain"
+)
+
+func TestStitchOverlapsSplitsTimedItemsAtSeamMiddle(t *testing.T) {
+	t.Parallel()
+
+	pieces := []stitchPiece{
+		{
+			offset: 0,
+			text:   "one two three four",
+			segments: []domain.Segment{
+				{Start: 0, End: 8, Text: "one two"},
+				{Start: 9, End: 11, Text: "three"},
+				{Start: 11, End: 13, Text: "four"},
+			},
+		},
+		{
+			offset:  10,
+			overlap: 2,
+			text:    "three four five",
+			segments: []domain.Segment{
+				{Start: 10, End: 11, Text: "three"},
+				{Start: 11, End: 13, Text: "four"},
+				{Start: 13, End: 15, Text: "five"},
+			},
+		},
+	}
+
+	stitchOverlaps(pieces)
+
+	if pieces[0].text != "one two three" {
+		t.Fatalf("first text = %q", pieces[0].text)
+	}
+	if pieces[1].text != "four five" {
+		t.Fatalf("second text = %q", pieces[1].text)
+	}
+	if len(pieces[0].segments) != 2 || len(pieces[1].segments) != 2 || pieces[1].segments[0].Start != 11 {
+		t.Fatalf("segments = %#v / %#v", pieces[0].segments, pieces[1].segments)
+	}
+}
+
+func TestStitchOverlapsDropsSpeechCoveredBySegmentAcrossCut(t *testing.T) {
+	t.Parallel()
+
+	pieces := []stitchPiece{
+		{
+			offset: 0,
+			segments: []domain.Segment{
+				{Start: 0, End: 8, Text: "one two"},
+				// Straddles the cut at 11 and already covers "four".
+				{Start: 9, End: 12.5, Text: "three four"},
+			},
+			words: []domain.Word{
+				{Start: 9, End: 10.5, Word: "three"},
+				{Start: 10.5, End: 12.5, Word: "four"},
+			},
+		},
+		{
+			offset:  10,
+			overlap: 2,
+			segments: []domain.Segment{
+				{Start: 10, End: 11, Text: "three"},
+				{Start: 11, End: 12.4, Text: "four"},
+				{Start: 12.5, End: 15, Text: "five"},
+			},
+			words: []domain.Word{
+				{Start: 10, End: 11, Word: "three"},
+				{Start: 11, End: 12.4, Word: "four"},
+				{Start: 12.5, End: 15, Word: "five"},
+			},
+		},
+	}
+
+	stitchOverlaps(pieces)
+
+	if pieces[0].text != "one two three four" || pieces[1].text != "five" {
+		t.Fatalf("texts = %q / %q", pieces[0].text, pieces[1].text)
+	}
+	if len(pieces[1].segments) != 1 || pieces[1].segments[0].Text != "five" {
+		t.Fatalf("second segments = %#v", pieces[1].segments)
+	}
+	var words []string
+	for _, piece := range pieces {
+		for _, word := range piece.words {
+			words = append(words, word.Word)
+		}
+	}
+	if strings.Join(words, " ") != "three four five" {
+		t.Fatalf("words = %v", words)
+	}
+}
+
+func TestStitchOverlapsDropsFuzzyRepeatedText(t *testing.T) {
+	t.Parallel()
+
+	pieces := []stitchPiece{
+		{offset: 0, text: "We will now talk about the quarterly results, and then"},
+		{offset: 600, overlap: 10, text: "the quarterly results and then questions from the floor."},
+	}
+
+	stitchOverlaps(pieces)
+
+	if pieces[1].text != "questions from the floor." {
+		t.Fatalf("second text = %q", pieces[1].text)
+	}
+}
+
+func TestDropRepeatedPrefixKeepsUnrelatedText(t *testing.T) {
+	t.Parallel()
+
+	got := dropRepeatedPrefix("this is the end", "the start of something new")
+	if got != "the start of something new" {
+		t.Fatalf("dropRepeatedPrefix = %q", got)
+	}
+}
@@ -0,0 +1,251 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/provider"
+)
+
+// watchedFile is a file that changed recently and waits for its size to
+// settle.
+type watchedFile struct {
+	size int64
+	due  time.Time
+}
+
+// watchRun is the state shared by the files of one watch session.
+type watchRun struct {
+	client     provider.Client
+	pool       *workerPool
+	manifest   *runManifest
+	cfg        config.Config
+	opts       config.WatchOptions
+	inputDir   string
+	outputRoot string
+}
+
+// Watch transcribes media files as they are written into the cfg.Input
+// directory until ctx is cancelled. Files already in the directory are
+// picked up on start. A file is transcribed once it was closed after
+// writing or moved in and its size stayed the same for opts.Debounce.
+// Cancelling ctx stops scheduling, interrupts running files and leaves
+// them in place for the next start.
+func (a *Application) Watch(ctx context.Context, cfg config.Config, opts config.WatchOptions) error {
+	if cfg.Input == "" || cfg.Input == stdinInput {
+		return errors.New("watch needs a directory in --input or WHISPER_CLI_INPUT")
+	}
+	client, cfg, err := a.prepareRun(cfg)
+	if err != nil {
+		return err
+	}
+
+	inputDir, err := a.FS.Abs(filepath.Clean(cfg.Input))
+	if err != nil {
+		return fmt.Errorf("resolve input path: %w", err)
+	}
+	info, err := a.FS.Stat(inputDir)
+	if err != nil {
+		return fmt.Errorf("stat input path %s: %w", inputDir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("watch input %s must be a directory", inputDir)
+	}
+	outputRoot, err := a.FS.Abs(filepath.Clean(cfg.OutputDir))
+	if err != nil {
+		return fmt.Errorf("resolve output dir: %w", err)
+	}
+	for _, dir := range []*string{&opts.DoneDir, &opts.FailedDir} {
+		if *dir == "" {
+			continue
+		}
+		if *dir, err = a.FS.Abs(filepath.Clean(*dir)); err != nil {
+			return fmt.Errorf("resolve watch directory: %w", err)
+		}
+		if err := a.FS.MkdirAll(*dir, 0o755); err != nil {
+			return fmt.Errorf("create watch directory: %w", err)
+		}
+	}
+	manifest, err := loadManifest(a.FS, outputRoot)
+	if err != nil {
+		return err
+	}
+
+	// Watch before listing, so a file written in between is not missed.
+	events, err := watchDir(ctx, inputDir)
+	if err != nil {
+		return err
+	}
+	entries, err := a.FS.ReadDir(inputDir)
+	if err != nil {
+		return fmt.Errorf("read input directory: %w", err)
+	}
+	var existing []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			existing = append(existing, filepath.Join(inputDir, entry.Name()))
+		}
+	}
+
+	a.Logger.Info().
+		Str("input", inputDir).
+		Str("output_dir", outputRoot).
+		Dur("debounce", opts.Debounce).
+		Int("file_jobs", opts.FileJobs).
+		Msg("watching directory for new media files")
+
+	return a.watchLoop(ctx, watchRun{
+		client:     client,
+		pool:       newWorkerPool(cfg.Concurrency, cfg.FFmpegJobs),
+		manifest:   manifest,
+		cfg:        cfg,
+		opts:       opts,
+		inputDir:   inputDir,
+		outputRoot: outputRoot,
+	}, existing, events)
+}
+
+func (a *Application) watchLoop(ctx context.Context, run watchRun, existing []string, events <-chan string) error {
+	var (
+		wg       sync.WaitGroup
+		pending  = make(map[string]watchedFile)
+		active   = make(map[string]bool)
+		finished = make(chan string)
+		jobs     = newSemaphore(run.opts.FileJobs)
+	)
+	ticker := time.NewTicker(max(run.opts.Debounce/4, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	note := func(file string) {
+		// Hidden files are typically partial uploads.
+		if active[file] || strings.HasPrefix(filepath.Base(file), ".") {
+			return
+		}
+		info, err := a.FS.Stat(file)
+		if err != nil || info.IsDir() {
+			delete(pending, file)
+			return
+		}
+		pending[file] = watchedFile{size: info.Size(), due: time.Now().Add(run.opts.Debounce)}
+	}
+	for _, file := range existing {
+		note(file)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			a.Logger.Info().Str("input", run.inputDir).Msg("stopped watching directory")
+			return nil
+		case file, ok := <-events:
+			if !ok {
+				wg.Wait()
+				if ctx.Err() != nil {
+					return nil
+				}
+				return errors.New("directory watch stopped unexpectedly")
+			}
+			note(file)
+		case file := <-finished:
+			delete(active, file)
+		case now := <-ticker.C:
+			for file, state := range pending {
+				if now.Before(state.due) {
+					continue
+				}
+				info, err := a.FS.Stat(file)
+				if err != nil {
+					// Renamed or removed, e.g. a temporary upload file.
+					delete(pending, file)
+					continue
+				}
+				if info.Size() != state.size {
+					pending[file] = watchedFile{size: info.Size(), due: now.Add(run.opts.Debounce)}
+					continue
+				}
+
+				delete(pending, file)
+				if !run.cfg.Scan.Accepts(filepath.Base(file), info.Size(), info.ModTime()) {
+					a.Logger.Debug().Str("file", file).Msg("skipping file filtered out by scan options")
+					continue
+				}
+				active[file] = true
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := jobs.acquire(ctx); err == nil {
+						if ok, reason := a.Audio.DetectMedia(ctx, file); ok {
+							a.processWatched(ctx, run, file)
+						} else {
+							a.Logger.Warn().
+								Str("file", file).
+								Str("reason", reason).
+								Msg("skipped file without decodable audio")
+						}
+						jobs.release()
+					}
+					select {
+					case finished <- file:
+					case <-ctx.Done():
+					}
+				}()
+			}
+		}
+	}
+}
+
+// processWatched transcribes one settled file and files it away into the
+// done or failed directory when those are configured.
+func (a *Application) processWatched(ctx context.Context, run watchRun, file string) {
+	// Files stay in the input directory without --done-dir, so a restarted
+	// session finds them again; the manifest is always consulted to skip
+	// the ones that are already transcribed.
+	cfg := run.cfg
+	cfg.Incremental = true
+	outputDir := mirrorOutputDirs(run.inputDir, run.outputRoot, []string{file})[file]
+	entry := a.processTracked(ctx, run.client, run.pool, run.manifest, cfg, file, outputDir)
+	if ctx.Err() != nil {
+		a.Logger.Warn().Str("file", file).Msg("interrupted; file stays in place for the next run")
+		return
+	}
+
+	target := run.opts.DoneDir
+	if entry.Status == fileFailed {
+		target = run.opts.FailedDir
+	}
+	if target == "" {
+		return
+	}
+	moved, err := a.moveInto(file, target)
+	if err != nil {
+		a.Logger.Error().Err(err).Str("file", file).Msg("failed to move processed file")
+		return
+	}
+	a.Logger.Info().Str("file", file).Str("moved_to", moved).Msg("moved processed file")
+}
+
+// moveInto moves file into dir, numbering the name when dir already holds
+// a file with the same name.
+func (a *Application) moveInto(file string, dir string) (string, error) {
+	ext := filepath.Ext(file)
+	stem := strings.TrimSuffix(filepath.Base(file), ext)
+	target := filepath.Join(dir, filepath.Base(file))
+	for n := 1; ; n++ {
+		if _, err := a.FS.Stat(target); err != nil {
+			break
+		}
+		target = filepath.Join(dir, stem+"-"+strconv.Itoa(n)+ext)
+	}
+	if err := a.FS.Rename(file, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
@@ -0,0 +1,290 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/config"
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+	"github.com/arykalin/whisper-cli/internal/provider"
+	"github.com/rs/zerolog"
+)
+
+func TestWatchLoopTranscribesSettledFilesAndMovesThem(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "in")
+	doneDir := filepath.Join(dir, "done")
+	failedDir := filepath.Join(dir, "failed")
+	for _, d := range []string{inputDir, doneDir, failedDir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	write := func(name string) string {
+		t.Helper()
+		file := filepath.Join(inputDir, name)
+		if err := os.WriteFile(file, []byte(name), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return file
+	}
+	existing := write("a.mp3")
+	broken := write("broken.mp3")
+	hidden := write(".upload.mp3")
+
+	client := fakeProvider{
+		name:         domain.ProviderOpenAI,
+		capabilities: map[string]domain.Capabilities{"whisper-1": {}},
+		responses: map[string]provider.Response{
+			"a-chunk": {Transcript: domain.Transcript{Text: "a"}},
+			"b-chunk": {Transcript: domain.Transcript{Text: "b"}},
+		},
+	}
+	app := &Application{
+		FS: fsx.OS{},
+		Audio: &fakeAudioPipeline{sourceChunks: map[string][]audio.Chunk{
+			existing:                         {{Number: 0, Path: "a-chunk"}},
+			broken:                           {{Number: 0, Path: "broken-chunk"}},
+			filepath.Join(inputDir, "b.mp3"): {{Number: 0, Path: "b-chunk"}},
+		}},
+		Registry: provider.NewRegistry(client),
+		Logger:   zerolog.New(io.Discard),
+		Env:      staticEnv{},
+	}
+	cfg := config.Config{
+		Input:        inputDir,
+		OutputDir:    filepath.Join(dir, "out"),
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 600,
+		Concurrency:  2,
+		FFmpegJobs:   1,
+	}
+
+	manifest, err := loadManifest(app.FS, cfg.OutputDir)
+	if err != nil {
+		t.Fatalf("loadManifest returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan string)
+	result := make(chan error, 1)
+	go func() {
+		result <- app.watchLoop(ctx, watchRun{
+			client:     client,
+			pool:       newWorkerPool(cfg.Concurrency, cfg.FFmpegJobs),
+			manifest:   manifest,
+			cfg:        cfg,
+			opts:       config.WatchOptions{Debounce: 20 * time.Millisecond, FileJobs: 1, DoneDir: doneDir, FailedDir: failedDir},
+			inputDir:   inputDir,
+			outputRoot: cfg.OutputDir,
+		}, []string{existing, broken, hidden}, events)
+	}()
+
+	events <- write("b.mp3")
+
+	expected := []string{
+		filepath.Join(doneDir, "a.mp3"),
+		filepath.Join(doneDir, "b.mp3"),
+		filepath.Join(failedDir, "broken.mp3"),
+		filepath.Join(dir, "out", "a", "transcript.txt"),
+		filepath.Join(dir, "out", "b", "transcript.txt"),
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for _, file := range expected {
+		for {
+			if _, err := os.Stat(file); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s did not appear", file)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if _, err := os.Stat(hidden); err != nil {
+		t.Fatalf("hidden file should stay in place: %v", err)
+	}
+
+	cancel()
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("watchLoop returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchLoop did not stop after cancel")
+	}
+}
+
+func TestWatchLoopSkipsFilesWithoutDecodableAudio(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "in")
+	failedDir := filepath.Join(dir, "failed")
+	for _, d := range []string{inputDir, failedDir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	notes := filepath.Join(inputDir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("not audio"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	pipeline := &fakeAudioPipeline{undecodable: map[string]string{notes: "no audio stream"}}
+	app := &Application{
+		FS:     fsx.OS{},
+		Audio:  pipeline,
+		Logger: zerolog.New(io.Discard),
+		Env:    staticEnv{},
+	}
+	cfg := config.Config{
+		Input:        inputDir,
+		OutputDir:    filepath.Join(dir, "out"),
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		ChunkSeconds: 600,
+		Concurrency:  1,
+		FFmpegJobs:   1,
+	}
+	manifest, err := loadManifest(app.FS, cfg.OutputDir)
+	if err != nil {
+		t.Fatalf("loadManifest returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- app.watchLoop(ctx, watchRun{
+			pool:       newWorkerPool(cfg.Concurrency, cfg.FFmpegJobs),
+			manifest:   manifest,
+			cfg:        cfg,
+			opts:       config.WatchOptions{Debounce: 10 * time.Millisecond, FileJobs: 1, FailedDir: failedDir},
+			inputDir:   inputDir,
+			outputRoot: cfg.OutputDir,
+		}, []string{notes}, make(chan string))
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !slices.Contains(pipeline.calls(), "detect:notes.txt") {
+		if time.Now().After(deadline) {
+			t.Fatal("notes.txt was never probed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-result; err != nil {
+		t.Fatalf("watchLoop returned error: %v", err)
+	}
+
+	if slices.Contains(pipeline.calls(), "prepare_input") {
+		t.Fatalf("expected no transcription, calls = %v", pipeline.calls())
+	}
+	if _, err := os.Stat(notes); err != nil {
+		t.Fatalf("skipped file should stay in place: %v", err)
+	}
+}
+
+func TestProcessWatchedSkipsFilesUpToDateInManifest(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "in")
+	doneDir := filepath.Join(dir, "done")
+	for _, d := range []string{inputDir, doneDir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	file := filepath.Join(inputDir, "a.mp3")
+	if err := os.WriteFile(file, []byte("audio"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	client := fakeProvider{
+		name:         domain.ProviderOpenAI,
+		capabilities: map[string]domain.Capabilities{"whisper-1": {}},
+		responses:    map[string]provider.Response{"a-chunk": {Transcript: domain.Transcript{Text: "a"}}},
+	}
+	pipeline := &fakeAudioPipeline{chunks: []audio.Chunk{{Number: 0, Path: "a-chunk"}}}
+	app := &Application{
+		FS:       fsx.OS{},
+		Audio:    pipeline,
+		Registry: provider.NewRegistry(client),
+		Logger:   zerolog.New(io.Discard),
+		Env:      staticEnv{},
+	}
+	cfg := config.Config{
+		Input:        inputDir,
+		OutputDir:    filepath.Join(dir, "out"),
+		Provider:     domain.ProviderOpenAI,
+		Model:        "whisper-1",
+		Outputs:      domain.ArtifactSet{},
+		ChunkSeconds: 600,
+		Concurrency:  1,
+		FFmpegJobs:   1,
+	}
+	manifest, err := loadManifest(app.FS, cfg.OutputDir)
+	if err != nil {
+		t.Fatalf("loadManifest returned error: %v", err)
+	}
+	run := watchRun{
+		client:     client,
+		pool:       newWorkerPool(cfg.Concurrency, cfg.FFmpegJobs),
+		manifest:   manifest,
+		cfg:        cfg,
+		inputDir:   inputDir,
+		outputRoot: cfg.OutputDir,
+	}
+
+	// The first session leaves the file in place; the next one, started
+	// without --incremental, must not transcribe it again.
+	app.processWatched(context.Background(), run, file)
+	run.opts.DoneDir = doneDir
+	app.processWatched(context.Background(), run, file)
+
+	if len(pipeline.prepareInputCalls) != 1 {
+		t.Fatalf("expected one transcription, got %d", len(pipeline.prepareInputCalls))
+	}
+	if _, err := os.Stat(filepath.Join(doneDir, "a.mp3")); err != nil {
+		t.Fatalf("skipped file should be moved to the done directory: %v", err)
+	}
+}
+
+func TestMoveIntoNumbersTakenNames(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "done")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(target, "talk.mp3"), []byte("old"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	file := filepath.Join(dir, "talk.mp3")
+	if err := os.WriteFile(file, []byte("new"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	app := &Application{FS: fsx.OS{}}
+	moved, err := app.moveInto(file, target)
+	if err != nil {
+		t.Fatalf("moveInto returned error: %v", err)
+	}
+	if moved != filepath.Join(target, "talk-1.mp3") {
+		t.Fatalf("moved to %s, want talk-1.mp3", moved)
+	}
+}
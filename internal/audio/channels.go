@@ -0,0 +1,33 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// prepareChannels encodes every channel into its own mono source with the
+// same timing filters, so per-channel transcripts share one timeline.
+func (s Service) prepareChannels(
+	ctx context.Context,
+	inputFile string,
+	workDir string,
+	prepared PreparedInput,
+	profile Profile,
+	sel selection,
+	filters []string,
+	channels int,
+) (PreparedInput, error) {
+	for channel := range channels {
+		channelFilters := append([]string{fmt.Sprintf("pan=mono|c0=c%d", channel)}, filters...)
+		channelPath := filepath.Join(workDir, fmt.Sprintf("source_ch%d%s", channel, profile.Format))
+		if err := s.convert(ctx, inputFile, channelPath, profile, sel, channelFilters); err != nil {
+			return PreparedInput{}, fmt.Errorf("channel %d: %w", channel, err)
+		}
+		prepared.ChannelPaths = append(prepared.ChannelPaths, channelPath)
+	}
+
+	prepared.ChunkSourcePath = prepared.ChannelPaths[0]
+	prepared.Converted = true
+	return prepared, nil
+}
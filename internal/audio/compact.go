@@ -0,0 +1,110 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// silencePadding is kept on both sides of a removed silence so word onsets
+// and trailing syllables are not clipped.
+const silencePadding = 0.25
+
+// Span is a stretch of kept audio that starts at Compact seconds in the
+// compacted source and at Original seconds in the input.
+type Span struct {
+	Compact  float64
+	Original float64
+	Duration float64
+}
+
+// TimeMap translates compacted source time back to input time. A nil map is
+// the identity.
+type TimeMap []Span
+
+// Original maps a start time in the compacted source to the input timeline.
+func (m TimeMap) Original(t float64) float64 {
+	if len(m) == 0 {
+		return t
+	}
+	idx := sort.Search(len(m), func(i int) bool { return m[i].Compact > t }) - 1
+	return m[max(idx, 0)].Original + t - m[max(idx, 0)].Compact
+}
+
+// OriginalEnd maps an end time; a time on a span boundary stays at the end of
+// the earlier span instead of jumping over the removed silence.
+func (m TimeMap) OriginalEnd(t float64) float64 {
+	if len(m) == 0 {
+		return t
+	}
+	idx := sort.Search(len(m), func(i int) bool { return m[i].Compact >= t }) - 1
+	return m[max(idx, 0)].Original + t - m[max(idx, 0)].Compact
+}
+
+// planKeptSpans returns the audio to keep when silences of at least
+// minDuration are removed, or nil when nothing would be removed.
+func planKeptSpans(total float64, minDuration float64, silences []silence) TimeMap {
+	var (
+		spans    TimeMap
+		position float64
+		compact  float64
+		removed  bool
+	)
+	for _, item := range silences {
+		if item.End-item.Start < minDuration {
+			continue
+		}
+		// Leading and trailing silence has no speech to protect.
+		cutStart, cutEnd := item.Start+silencePadding, item.End-silencePadding
+		if item.Start <= 0 {
+			cutStart = 0
+		}
+		if item.End >= total {
+			cutEnd = total
+		}
+		cutStart = max(cutStart, position)
+		if cutEnd <= cutStart {
+			continue
+		}
+		if cutStart > position {
+			spans = append(spans, Span{Compact: compact, Original: position, Duration: cutStart - position})
+			compact += cutStart - position
+		}
+		position = cutEnd
+		removed = true
+	}
+	if !removed {
+		return nil
+	}
+	if total > position {
+		spans = append(spans, Span{Compact: compact, Original: position, Duration: total - position})
+	}
+	return spans
+}
+
+// silenceFilter selects the kept spans and closes the gaps between them.
+func silenceFilter(spans TimeMap) string {
+	parts := make([]string, 0, len(spans))
+	for _, span := range spans {
+		parts = append(parts, fmt.Sprintf(
+			"between(t,%s,%s)",
+			strconv.FormatFloat(span.Original, 'f', 3, 64),
+			strconv.FormatFloat(span.Original+span.Duration, 'f', 3, 64),
+		))
+	}
+	return "aselect='" + strings.Join(parts, "+") + "',asetpts=N/SR/TB"
+}
+
+func (s Service) planSilenceRemoval(ctx context.Context, inputFile string, minDuration float64, mapArgs []string) (TimeMap, error) {
+	total, err := s.duration(ctx, inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("duration for %s: %w", inputFile, err)
+	}
+	silences, err := s.detectSilences(ctx, inputFile, minDuration, mapArgs)
+	if err != nil {
+		return nil, err
+	}
+	return planKeptSpans(total, minDuration, silences), nil
+}
@@ -0,0 +1,97 @@
+package audio
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WorkDirName is the directory under each output directory that holds
+// intermediate files. Recursive scans never descend into it.
+const WorkDirName = "_work"
+
+// ScanOptions filters directory input.
+type ScanOptions struct {
+	Recursive bool
+	// Include and Exclude are path.Match globs. Patterns with a slash match
+	// the slash-separated path relative to the scanned directory, others
+	// match the file or directory name. Exclude also prunes directories.
+	Include       []string
+	Exclude       []string
+	MinSize       int64
+	ModifiedSince time.Time
+	// OutputDir is the absolute output root. A recursive scan skips it so
+	// an output directory inside the input does not feed its own results
+	// back into the run.
+	OutputDir string
+}
+
+func (o ScanOptions) excluded(rel string) bool {
+	return matchesAny(o.Exclude, rel)
+}
+
+// Accepts applies the name, size and time filters, which are cheap enough
+// to run before a file is probed. rel is slash-separated and relative to
+// the scanned directory.
+func (o ScanOptions) Accepts(rel string, size int64, modified time.Time) bool {
+	if o.excluded(rel) {
+		return false
+	}
+	if len(o.Include) > 0 && !matchesAny(o.Include, rel) {
+		return false
+	}
+	if size < o.MinSize {
+		return false
+	}
+	return o.ModifiedSince.IsZero() || !modified.Before(o.ModifiedSince)
+}
+
+func matchesAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		target := path.Base(rel)
+		if strings.Contains(pattern, "/") {
+			target = rel
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SkippedFile is a directory entry that was not queued for transcription.
+type SkippedFile struct {
+	Path   string
+	Reason string
+}
+
+// DetectMedia reports whether path has a decodable audio stream and, if it
+// has not, why. Known extensions are accepted without running ffprobe.
+func (s Service) DetectMedia(ctx context.Context, path string) (bool, string) {
+	if isSupportedMedia(filepath.Base(path)) {
+		return true, ""
+	}
+
+	stdout, stderr, err := s.Runner.Run(
+		ctx,
+		"ffprobe",
+		"-v", "error",
+		"-select_streams", "a",
+		"-show_entries", "stream=codec_type",
+		"-of", "csv=p=0",
+		path,
+	)
+	if err != nil {
+		reason := strings.TrimSpace(string(stderr))
+		if reason == "" {
+			reason = err.Error()
+		}
+		return false, "not a media file: " + reason
+	}
+	if !strings.Contains(string(stdout), "audio") {
+		return false, "no audio stream"
+	}
+	return true, ""
+}
@@ -0,0 +1,64 @@
+package audio
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FilterPreset names a tuned ffmpeg audio filter.
+type FilterPreset string
+
+const (
+	FilterLoudnorm FilterPreset = "loudnorm"
+	FilterAfftdn   FilterPreset = "afftdn"
+	FilterHighpass FilterPreset = "highpass"
+	FilterLowpass  FilterPreset = "lowpass"
+)
+
+var filterPresets = map[FilterPreset]string{
+	// loudnorm resamples to 192 kHz internally, so bring the rate back down.
+	FilterLoudnorm: "loudnorm=I=-16:TP=-1.5:LRA=11,aresample=48000",
+	FilterAfftdn:   "afftdn=nf=-25",
+	// highpass removes mains hum and rumble below the voice band.
+	FilterHighpass: "highpass=f=80",
+	FilterLowpass:  "lowpass=f=8000",
+}
+
+// ParseFilterPresets parses a comma-separated preset list such as
+// "highpass,afftdn,loudnorm". The order is kept.
+func ParseFilterPresets(value string) ([]FilterPreset, error) {
+	var presets []FilterPreset
+	for _, part := range strings.Split(value, ",") {
+		preset := FilterPreset(strings.ToLower(strings.TrimSpace(part)))
+		if preset == "" {
+			continue
+		}
+		if _, ok := filterPresets[preset]; !ok {
+			return nil, fmt.Errorf("unsupported audio filter preset %q", part)
+		}
+		presets = append(presets, preset)
+	}
+	return presets, nil
+}
+
+func FilterPresetNames() []FilterPreset {
+	names := make([]FilterPreset, 0, len(filterPresets))
+	for name := range filterPresets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// FilterChain joins presets and a raw -af expression into one filter graph.
+func FilterChain(presets []FilterPreset, raw string) string {
+	parts := make([]string, 0, len(presets)+1)
+	for _, preset := range presets {
+		parts = append(parts, filterPresets[preset])
+	}
+	if raw = strings.TrimSpace(raw); raw != "" {
+		parts = append(parts, raw)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,83 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type overlapWindow struct {
+	Start    float64
+	Duration float64
+	Overlap  float64
+}
+
+func (s Service) streamOverlapChunks(ctx context.Context, inputFile string, outputPattern string, opts ChunkOptions, emit func(Chunk) error) error {
+	total, err := s.duration(ctx, inputFile)
+	if err != nil {
+		return fmt.Errorf("duration for %s: %w", inputFile, err)
+	}
+
+	windows := planOverlapWindows(total, float64(opts.Seconds), opts.Overlap)
+	for index, window := range windows {
+		chunkFile := fmt.Sprintf(outputPattern, index)
+		if err := s.extract(ctx, inputFile, chunkFile, window.Start, window.Duration); err != nil {
+			return err
+		}
+		err := emit(Chunk{
+			Number:   index,
+			Path:     chunkFile,
+			Offset:   window.Start,
+			Duration: window.Duration,
+			Overlap:  window.Overlap,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planOverlapWindows lays chunks out every target seconds; each chunk except
+// the last runs overlap seconds into the next one.
+func planOverlapWindows(total float64, target float64, overlap float64) []overlapWindow {
+	if total <= 0 || target <= 0 {
+		return nil
+	}
+
+	var windows []overlapWindow
+	for start := 0.0; start < total; start += target {
+		window := overlapWindow{
+			Start:    start,
+			Duration: math.Min(target+overlap, total-start),
+		}
+		if start > 0 {
+			window.Overlap = overlap
+		}
+		windows = append(windows, window)
+		if start+target+overlap >= total {
+			break
+		}
+	}
+	return windows
+}
+
+func (s Service) extract(ctx context.Context, inputPath string, outputPath string, start float64, duration float64) error {
+	args := []string{
+		"-y",
+		"-ss", strconv.FormatFloat(start, 'f', 3, 64),
+		"-t", strconv.FormatFloat(duration, 'f', 3, 64),
+		"-i", inputPath,
+		"-c", "copy",
+	}
+	args = append(args, bitexactArgs...)
+	args = append(args, outputPath)
+
+	_, stderr, err := s.Runner.Run(ctx, "ffmpeg", args...)
+	if err != nil {
+		return fmt.Errorf("extract chunk %s: %w: %s", outputPath, err, strings.TrimSpace(string(stderr)))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package audio
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProfileName selects how inputs are re-encoded before chunking.
+type ProfileName string
+
+const (
+	ProfileDefault    ProfileName = "default"
+	ProfileSpeechOpus ProfileName = "speech-opus"
+	ProfileSpeechFLAC ProfileName = "speech-flac"
+	ProfileSpeechAAC  ProfileName = "speech-aac"
+)
+
+// Profile describes the container and encoder used for the chunk source.
+type Profile struct {
+	Name ProfileName
+	// Format is the extension of the encoded source and of its chunks.
+	Format string
+	// Passthrough lists input extensions that are chunked as-is instead of
+	// being re-encoded.
+	Passthrough []string
+	EncodeArgs  []string
+}
+
+var profiles = map[ProfileName]Profile{
+	ProfileDefault: {
+		Name:        ProfileDefault,
+		Format:      ".m4a",
+		Passthrough: []string{".m4a"},
+		EncodeArgs:  []string{"-c:a", "aac"},
+	},
+	ProfileSpeechOpus: {
+		Name:       ProfileSpeechOpus,
+		Format:     ".ogg",
+		EncodeArgs: []string{"-ac", "1", "-ar", "16000", "-c:a", "libopus", "-b:a", "24k", "-application", "voip"},
+	},
+	ProfileSpeechFLAC: {
+		Name:       ProfileSpeechFLAC,
+		Format:     ".flac",
+		EncodeArgs: []string{"-ac", "1", "-ar", "16000", "-c:a", "flac"},
+	},
+	ProfileSpeechAAC: {
+		Name:       ProfileSpeechAAC,
+		Format:     ".m4a",
+		EncodeArgs: []string{"-ac", "1", "-ar", "16000", "-c:a", "aac", "-b:a", "32k"},
+	},
+}
+
+// ParseProfileName validates a profile name. An empty value is kept empty so
+// callers can fall back to the provider default.
+func ParseProfileName(value string) (ProfileName, error) {
+	name := ProfileName(strings.ToLower(strings.TrimSpace(value)))
+	if name == "" {
+		return "", nil
+	}
+	if _, ok := profiles[name]; !ok {
+		return "", fmt.Errorf("unsupported audio profile %q", value)
+	}
+	return name, nil
+}
+
+func LookupProfile(name ProfileName) (Profile, bool) {
+	profile, ok := profiles[name]
+	return profile, ok
+}
+
+func ProfileNames() []ProfileName {
+	names := make([]ProfileName, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (p Profile) passthrough(inputFile string) bool {
+	return slices.ContainsFunc(p.Passthrough, func(ext string) bool {
+		return strings.EqualFold(ext, extension(inputFile))
+	})
+}
@@ -0,0 +1,158 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TimeRange is a window of the input in seconds. A zero End runs to the end
+// of the input.
+type TimeRange struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end,omitempty"`
+}
+
+func (r TimeRange) seekArgs() []string {
+	args := []string{"-ss", strconv.FormatFloat(r.Start, 'f', 3, 64)}
+	if r.End > 0 {
+		args = append(args, "-to", strconv.FormatFloat(r.End, 'f', 3, 64))
+	}
+	return args
+}
+
+// ParseTimestamp reads seconds, MM:SS or HH:MM:SS, with optional fractions.
+func ParseTimestamp(value string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+
+	var seconds float64
+	for idx, part := range parts {
+		number, err := strconv.ParseFloat(part, 64)
+		if err != nil || number < 0 || (idx > 0 && number >= 60) {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		seconds = seconds*60 + number
+	}
+	return seconds, nil
+}
+
+// ParseTimeRanges reads a comma-separated list such as "10:00-20:00,1:05:00-"
+// where an empty end runs to the end of the input. Ranges must be ordered
+// and must not overlap.
+func ParseTimeRanges(value string) ([]TimeRange, error) {
+	var ranges []TimeRange
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		startRaw, endRaw, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid range %q; use START-END", part)
+		}
+		start, err := ParseTimestamp(startRaw)
+		if err != nil {
+			return nil, err
+		}
+		var end float64
+		if strings.TrimSpace(endRaw) != "" {
+			if end, err = ParseTimestamp(endRaw); err != nil {
+				return nil, err
+			}
+		}
+		ranges = append(ranges, TimeRange{Start: start, End: end})
+	}
+	if err := validateTimeRanges(ranges); err != nil {
+		return nil, err
+	}
+	return ranges, nil
+}
+
+func validateTimeRanges(ranges []TimeRange) error {
+	for idx, item := range ranges {
+		if item.End > 0 && item.End <= item.Start {
+			return fmt.Errorf("range end %.3f must be after start %.3f", item.End, item.Start)
+		}
+		if idx == 0 {
+			continue
+		}
+		previous := ranges[idx-1]
+		if previous.End == 0 || item.Start < previous.End {
+			return errors.New("ranges must be in order and must not overlap")
+		}
+	}
+	return nil
+}
+
+// rangeTimeMap maps the concatenated ranges back to the input timeline.
+func (s Service) rangeTimeMap(ctx context.Context, inputFile string, ranges []TimeRange) (TimeMap, error) {
+	var (
+		spans   TimeMap
+		compact float64
+	)
+	for _, item := range ranges {
+		end := item.End
+		if end == 0 {
+			total, err := s.duration(ctx, inputFile)
+			if err != nil {
+				return nil, fmt.Errorf("duration for %s: %w", inputFile, err)
+			}
+			end = total
+		}
+		if end <= item.Start {
+			return nil, fmt.Errorf("range start %.3f is past the end of %s", item.Start, inputFile)
+		}
+		spans = append(spans, Span{Compact: compact, Original: item.Start, Duration: end - item.Start})
+		compact += end - item.Start
+	}
+	return spans, nil
+}
+
+// selection is the part of the input that convert reads: an optional audio
+// stream and optional time ranges, concatenated when there are several.
+type selection struct {
+	stream *AudioStream
+	ranges []TimeRange
+}
+
+func (sel selection) inputArgs(inputPath string, filters []string) []string {
+	if len(sel.ranges) <= 1 {
+		var args []string
+		if len(sel.ranges) == 1 {
+			args = append(args, sel.ranges[0].seekArgs()...)
+		}
+		args = append(args, "-i", inputPath)
+		if sel.stream != nil {
+			args = append(args, sel.stream.mapArgs()...)
+		}
+		args = append(args, "-vn")
+		if len(filters) > 0 {
+			args = append(args, "-af", strings.Join(filters, ","))
+		}
+		return args
+	}
+
+	var (
+		args []string
+		pads strings.Builder
+	)
+	for idx, item := range sel.ranges {
+		args = append(args, item.seekArgs()...)
+		args = append(args, "-i", inputPath)
+		if sel.stream != nil {
+			fmt.Fprintf(&pads, "[%d:a:%d]", idx, sel.stream.Track)
+		} else {
+			fmt.Fprintf(&pads, "[%d:a]", idx)
+		}
+	}
+	graph := fmt.Sprintf("%sconcat=n=%d:v=0:a=1", pads.String(), len(sel.ranges))
+	if len(filters) > 0 {
+		graph += "," + strings.Join(filters, ",")
+	}
+	return append(args, "-filter_complex", graph+"[out]", "-map", "[out]")
+}
@@ -2,7 +2,9 @@ package audio
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -17,19 +19,94 @@ type Chunk struct {
 	Path     string
 	Offset   float64
 	Duration float64
+	// Overlap is how many leading seconds of this chunk repeat the end of
+	// the previous chunk.
+	Overlap float64
+}
+
+type ChunkMode string
+
+const (
+	ChunkModeFixed   ChunkMode = "fixed"
+	ChunkModeSilence ChunkMode = "silence"
+)
+
+func ParseChunkMode(value string) (ChunkMode, error) {
+	switch mode := ChunkMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "", ChunkModeFixed:
+		return ChunkModeFixed, nil
+	case ChunkModeSilence:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported chunk mode %q", value)
+	}
+}
+
+// ChunkOptions controls how the prepared source is cut into upload chunks.
+type ChunkOptions struct {
+	Seconds int
+	Mode    ChunkMode
+	// SilenceTolerance is how far, in seconds, a silence-aware cut may move
+	// away from the Seconds target to land in a pause.
+	SilenceTolerance float64
+	// Overlap makes neighbouring chunks share this many seconds of audio.
+	Overlap float64
+	// MaxBytes is the provider upload limit; zero means unlimited.
+	MaxBytes int64
+	// UploadFormats are the extensions, without the dot, the provider
+	// accepts. A chunk over MaxBytes is re-encoded to one of them; empty
+	// means any format.
+	UploadFormats []string
+}
+
+// InputOptions controls how an input is turned into the chunk source.
+type InputOptions struct {
+	// Profile selects the encoding; the zero value means ProfileDefault.
+	Profile Profile
+	// RemoveSilence strips silences of at least this many seconds; zero
+	// keeps the audio intact.
+	RemoveSilence float64
+	// Speed plays the audio faster with atempo; values of 0 and 1 keep the
+	// original tempo.
+	Speed float64
+	// Filter is an ffmpeg -af chain, see FilterChain.
+	Filter string
+	// AudioTrack selects the audio stream by track number or language tag;
+	// empty uses ffmpeg's default stream.
+	AudioTrack string
+	// Channels splits the first Channels channels into separate mono
+	// sources; zero keeps the channels mixed.
+	Channels int
+	// Ranges limits the source to these windows of the input.
+	Ranges []TimeRange
 }
 
 type PreparedInput struct {
 	OriginalPath    string
 	ChunkSourcePath string
 	Converted       bool
+	// TimeMap maps chunk source time back to the input; nil when the source
+	// keeps the input timeline.
+	TimeMap TimeMap
+	// Speed is the applied atempo factor, zero when the tempo is unchanged.
+	// Source times multiplied by it are on the TimeMap timeline.
+	Speed float64
+	// Filter is the applied user filter chain.
+	Filter string
+	// AudioStream is the selected stream, nil for ffmpeg's default stream.
+	AudioStream *AudioStream
+	// ChannelPaths holds one mono source per split channel, in channel
+	// order; ChunkSourcePath is then the first of them.
+	ChannelPaths []string
 }
 
 type Pipeline interface {
 	EnsureBinaries() error
-	CollectMediaFiles(dir string) ([]string, error)
-	PrepareInput(ctx context.Context, inputFile string, workDir string) (PreparedInput, error)
-	PrepareChunks(ctx context.Context, inputFile string, workDir string, chunkSeconds int) ([]Chunk, error)
+	CollectMediaFiles(ctx context.Context, dir string, opts ScanOptions) ([]string, []SkippedFile, error)
+	DetectMedia(ctx context.Context, path string) (bool, string)
+	PrepareInput(ctx context.Context, inputFile string, workDir string, opts InputOptions) (PreparedInput, error)
+	StreamChunks(ctx context.Context, inputFile string, workDir string, opts ChunkOptions, out chan<- Chunk) error
+	ListAudioStreams(ctx context.Context, inputFile string) ([]AudioStream, error)
 }
 
 type Service struct {
@@ -37,14 +114,22 @@ type Service struct {
 	Runner execx.Runner
 }
 
+// supportedMediaExt is the fast path of media detection; other files are
+// accepted when ffprobe finds an audio stream.
 var supportedMediaExt = map[string]struct{}{
+	".aac":  {},
 	".flac": {},
 	".m4a":  {},
+	".m4b":  {},
+	".mkv":  {},
+	".mov":  {},
 	".mp3":  {},
 	".mp4":  {},
 	".mpeg": {},
 	".mpga": {},
+	".oga":  {},
 	".ogg":  {},
+	".opus": {},
 	".wav":  {},
 	".webm": {},
 }
@@ -58,136 +143,197 @@ func (s Service) EnsureBinaries() error {
 	return nil
 }
 
-func (s Service) CollectMediaFiles(dir string) ([]string, error) {
-	entries, err := s.FS.ReadDir(dir)
+// CollectMediaFiles returns the files in dir that pass opts and carry an
+// audio stream, and the media candidates skipped with the reason.
+func (s Service) CollectMediaFiles(ctx context.Context, dir string, opts ScanOptions) ([]string, []SkippedFile, error) {
+	var (
+		files   []string
+		skipped []SkippedFile
+	)
+	if err := s.scanDir(ctx, dir, "", opts, &files, &skipped); err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(files)
+	return files, skipped, nil
+}
+
+func (s Service) scanDir(ctx context.Context, root string, rel string, opts ScanOptions, files *[]string, skipped *[]SkippedFile) error {
+	entries, err := s.FS.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var files []string
 	for _, entry := range entries {
+		entryRel := path.Join(rel, entry.Name())
 		if entry.IsDir() {
+			if !opts.Recursive || entry.Name() == WorkDirName || opts.excluded(entryRel) {
+				continue
+			}
+			if opts.OutputDir != "" && filepath.Join(root, filepath.FromSlash(entryRel)) == opts.OutputDir {
+				continue
+			}
+			if err := s.scanDir(ctx, root, entryRel, opts, files, skipped); err != nil {
+				return err
+			}
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !opts.Accepts(entryRel, info.Size(), info.ModTime()) {
 			continue
 		}
-		if isSupportedMedia(entry.Name()) {
-			files = append(files, filepath.Join(dir, entry.Name()))
+
+		filePath := filepath.Join(root, filepath.FromSlash(entryRel))
+		if ok, reason := s.DetectMedia(ctx, filePath); !ok {
+			*skipped = append(*skipped, SkippedFile{Path: filePath, Reason: reason})
+			continue
 		}
+		*files = append(*files, filePath)
 	}
-
-	sort.Strings(files)
-	return files, nil
+	return nil
 }
 
-func (s Service) PrepareInput(ctx context.Context, inputFile string, workDir string) (PreparedInput, error) {
+func (s Service) PrepareInput(ctx context.Context, inputFile string, workDir string, opts InputOptions) (PreparedInput, error) {
 	if err := s.FS.MkdirAll(workDir, 0o755); err != nil {
 		return PreparedInput{}, fmt.Errorf("create work directory: %w", err)
 	}
 
+	profile := opts.Profile
+	if profile.Name == "" {
+		profile = profiles[ProfileDefault]
+	}
+
 	prepared := PreparedInput{
 		OriginalPath:    inputFile,
 		ChunkSourcePath: inputFile,
 	}
-	if strings.EqualFold(filepath.Ext(inputFile), ".m4a") {
-		return prepared, nil
-	}
 
-	convertedPath := filepath.Join(workDir, "source.m4a")
-	if err := s.convertToM4A(ctx, inputFile, convertedPath); err != nil {
-		return PreparedInput{}, err
+	sel := selection{ranges: opts.Ranges}
+	if opts.AudioTrack != "" || opts.Channels > 0 {
+		streams, err := s.ListAudioStreams(ctx, inputFile)
+		if err != nil {
+			return PreparedInput{}, fmt.Errorf("list audio streams of %s: %w", inputFile, err)
+		}
+		// Channel splitting needs a known channel layout, so it pins the
+		// first audio stream unless a track is selected.
+		selector := opts.AudioTrack
+		if selector == "" {
+			selector = "0"
+		}
+		stream, err := SelectAudioStream(streams, selector)
+		if err != nil {
+			return PreparedInput{}, err
+		}
+		if opts.Channels > 0 && stream.Channels < opts.Channels {
+			return PreparedInput{}, fmt.Errorf("audio track %d has %d channels, %d channel labels given", stream.Track, stream.Channels, opts.Channels)
+		}
+		prepared.AudioStream = &stream
+		sel.stream = &stream
 	}
 
-	prepared.ChunkSourcePath = convertedPath
-	prepared.Converted = true
-	return prepared, nil
-}
-
-func (s Service) PrepareChunks(ctx context.Context, inputFile string, workDir string, chunkSeconds int) ([]Chunk, error) {
-	if chunkSeconds <= 0 {
-		return nil, fmt.Errorf("chunk_seconds must be greater than zero")
+	if len(opts.Ranges) > 0 {
+		if opts.RemoveSilence > 0 {
+			return PreparedInput{}, errors.New("silence removal cannot be combined with time ranges")
+		}
+		timeMap, err := s.rangeTimeMap(ctx, inputFile, opts.Ranges)
+		if err != nil {
+			return PreparedInput{}, err
+		}
+		prepared.TimeMap = timeMap
 	}
 
-	outputPattern := filepath.Join(workDir, "chunk_%03d.m4a")
-	if err := s.FS.MkdirAll(workDir, 0o755); err != nil {
-		return nil, fmt.Errorf("create work directory: %w", err)
+	var filters []string
+	if opts.RemoveSilence > 0 {
+		var mapArgs []string
+		if sel.stream != nil {
+			mapArgs = sel.stream.mapArgs()
+		}
+		timeMap, err := s.planSilenceRemoval(ctx, inputFile, opts.RemoveSilence, mapArgs)
+		if err != nil {
+			return PreparedInput{}, err
+		}
+		if len(timeMap) > 0 {
+			prepared.TimeMap = timeMap
+			filters = append(filters, silenceFilter(timeMap))
+		}
+	}
+	// User filters run on the kept audio and before atempo, which is the
+	// only filter that changes timing.
+	if filter := strings.TrimSpace(opts.Filter); filter != "" {
+		prepared.Filter = filter
+		filters = append(filters, filter)
+	}
+	if opts.Speed > 0 && opts.Speed != 1 {
+		prepared.Speed = opts.Speed
+		filters = append(filters, "atempo="+strconv.FormatFloat(opts.Speed, 'f', -1, 64))
+	}
+	if opts.Channels > 0 {
+		return s.prepareChannels(ctx, inputFile, workDir, prepared, profile, sel, filters, opts.Channels)
+	}
+	if sel.stream == nil && len(sel.ranges) == 0 && len(filters) == 0 && profile.passthrough(inputFile) {
+		return prepared, nil
 	}
 
-	if err := s.split(ctx, inputFile, outputPattern, chunkSeconds); err != nil {
-		return nil, err
+	convertedPath := filepath.Join(workDir, "source"+profile.Format)
+	if err := s.convert(ctx, inputFile, convertedPath, profile, sel, filters); err != nil {
+		return PreparedInput{}, err
 	}
 
-	return s.collectChunks(ctx, outputPattern)
+	prepared.ChunkSourcePath = convertedPath
+	prepared.Converted = true
+	return prepared, nil
 }
 
 func isSupportedMedia(name string) bool {
-	_, ok := supportedMediaExt[strings.ToLower(filepath.Ext(name))]
+	_, ok := supportedMediaExt[extension(name)]
 	return ok
 }
 
-func (s Service) convertToM4A(ctx context.Context, inputPath string, outputPath string) error {
-	_, stderr, err := s.Runner.Run(
-		ctx,
-		"ffmpeg",
-		"-y",
-		"-i", inputPath,
-		"-vn",
-		"-c:a", "aac",
-		outputPath,
-	)
+func extension(name string) string {
+	return strings.ToLower(filepath.Ext(name))
+}
+
+// bitexactArgs keep the muxer from writing random stream serials and
+// version tags, so the same input gives byte-identical chunks on every run
+// and chunk checkpoints, which are keyed by content, stay valid.
+var bitexactArgs = []string{"-fflags", "+bitexact"}
+
+func (s Service) convert(ctx context.Context, inputPath string, outputPath string, profile Profile, sel selection, filters []string) error {
+	args := append([]string{"-y"}, sel.inputArgs(inputPath, filters)...)
+	args = append(args, profile.EncodeArgs...)
+	args = append(args, bitexactArgs...)
+	args = append(args, outputPath)
+
+	_, stderr, err := s.Runner.Run(ctx, "ffmpeg", args...)
 	if err != nil {
-		return fmt.Errorf("convert input to m4a: %w: %s", err, strings.TrimSpace(string(stderr)))
+		return fmt.Errorf("convert input to %s: %w: %s", strings.TrimPrefix(profile.Format, "."), err, strings.TrimSpace(string(stderr)))
 	}
 	return nil
 }
 
-func (s Service) split(ctx context.Context, inputPath string, outputPattern string, chunkSeconds int) error {
-	_, stderr, err := s.Runner.Run(
-		ctx,
-		"ffmpeg",
+func (s Service) split(ctx context.Context, inputPath string, outputPattern string, listPath string, chunkSeconds int) error {
+	args := []string{
 		"-y",
 		"-i", inputPath,
 		"-f", "segment",
 		"-segment_time", strconv.Itoa(chunkSeconds),
-		"-c", "copy",
-		outputPattern,
-	)
+	}
+	args = append(args, segmentListArgs(listPath)...)
+	args = append(args, "-c", "copy")
+	args = append(args, bitexactArgs...)
+	args = append(args, outputPattern)
+
+	_, stderr, err := s.Runner.Run(ctx, "ffmpeg", args...)
 	if err != nil {
 		return fmt.Errorf("split audio: %w: %s", err, strings.TrimSpace(string(stderr)))
 	}
 	return nil
 }
 
-func (s Service) collectChunks(ctx context.Context, outputPattern string) ([]Chunk, error) {
-	var (
-		chunks []Chunk
-		offset float64
-	)
-
-	for index := 0; ; index++ {
-		chunkFile := fmt.Sprintf(outputPattern, index)
-		if _, err := s.FS.Stat(chunkFile); err != nil {
-			if index == 0 {
-				return nil, fmt.Errorf("no chunks generated for %s", outputPattern)
-			}
-			break
-		}
-
-		duration, err := s.duration(ctx, chunkFile)
-		if err != nil {
-			return nil, fmt.Errorf("duration for %s: %w", chunkFile, err)
-		}
-
-		chunks = append(chunks, Chunk{
-			Number:   index,
-			Path:     chunkFile,
-			Offset:   offset,
-			Duration: duration,
-		})
-		offset += duration
-	}
-
-	return chunks, nil
-}
-
 func (s Service) duration(ctx context.Context, filePath string) (float64, error) {
 	stdout, stderr, err := s.Runner.Run(
 		ctx,
@@ -3,10 +3,15 @@ package audio
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/arykalin/whisper-cli/internal/platform/fsx"
 )
@@ -17,6 +22,7 @@ type runCall struct {
 }
 
 type fakeRunner struct {
+	mu          sync.Mutex
 	calls       []runCall
 	lookPathErr map[string]error
 	runFunc     func(ctx context.Context, name string, args ...string) ([]byte, []byte, error)
@@ -30,16 +36,37 @@ func (f *fakeRunner) LookPath(name string) (string, error) {
 }
 
 func (f *fakeRunner) Run(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+	f.mu.Lock()
 	f.calls = append(f.calls, runCall{
 		name: name,
 		args: append([]string(nil), args...),
 	})
+	f.mu.Unlock()
 	if f.runFunc != nil {
 		return f.runFunc(ctx, name, args...)
 	}
 	return nil, nil, nil
 }
 
+// collectChunks drains StreamChunks and returns the chunks once splitting
+// has finished.
+func collectChunks(service Service, inputFile string, workDir string, opts ChunkOptions) ([]Chunk, error) {
+	out := make(chan Chunk)
+	done := make(chan error, 1)
+	go func() {
+		done <- service.StreamChunks(context.Background(), inputFile, workDir, opts, out)
+	}()
+
+	var chunks []Chunk
+	for chunk := range out {
+		chunks = append(chunks, chunk)
+	}
+	if err := <-done; err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
 func TestCollectMediaFilesFiltersAndSorts(t *testing.T) {
 	t.Parallel()
 
@@ -53,11 +80,18 @@ func TestCollectMediaFilesFiltersAndSorts(t *testing.T) {
 		t.Fatalf("mkdir nested: %v", err)
 	}
 
-	service := Service{FS: fsx.OS{}}
-	files, err := service.CollectMediaFiles(dir)
+	runner := &fakeRunner{}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+	files, skipped, err := service.CollectMediaFiles(context.Background(), dir, ScanOptions{})
 	if err != nil {
 		t.Fatalf("CollectMediaFiles returned error: %v", err)
 	}
+	if len(skipped) != 1 || skipped[0].Path != filepath.Join(dir, "notes.txt") || skipped[0].Reason != "no audio stream" {
+		t.Fatalf("skipped = %#v", skipped)
+	}
+	if len(runner.calls) != 1 {
+		t.Fatalf("ffprobe calls = %#v, want only notes.txt probed", runner.calls)
+	}
 
 	want := []string{
 		filepath.Join(dir, "a.m4a"),
@@ -87,7 +121,7 @@ func TestPrepareInputSkipsConversionForM4A(t *testing.T) {
 	runner := &fakeRunner{}
 	service := Service{FS: fsx.OS{}, Runner: runner}
 
-	prepared, err := service.PrepareInput(context.Background(), input, workDir)
+	prepared, err := service.PrepareInput(context.Background(), input, workDir, InputOptions{})
 	if err != nil {
 		t.Fatalf("PrepareInput returned error: %v", err)
 	}
@@ -122,7 +156,7 @@ func TestPrepareInputConvertsNonM4AInput(t *testing.T) {
 	runner := &fakeRunner{}
 	service := Service{FS: fsx.OS{}, Runner: runner}
 
-	prepared, err := service.PrepareInput(context.Background(), input, workDir)
+	prepared, err := service.PrepareInput(context.Background(), input, workDir, InputOptions{})
 	if err != nil {
 		t.Fatalf("PrepareInput returned error: %v", err)
 	}
@@ -143,12 +177,57 @@ func TestPrepareInputConvertsNonM4AInput(t *testing.T) {
 	if runner.calls[0].name != "ffmpeg" {
 		t.Fatalf("command = %s, want ffmpeg", runner.calls[0].name)
 	}
-	wantArgs := []string{"-y", "-i", input, "-vn", "-c:a", "aac", wantOutput}
+	wantArgs := []string{"-y", "-i", input, "-vn", "-c:a", "aac", "-fflags", "+bitexact", wantOutput}
+	if strings.Join(runner.calls[0].args, "\n") != strings.Join(wantArgs, "\n") {
+		t.Fatalf("args = %v, want %v", runner.calls[0].args, wantArgs)
+	}
+}
+
+func TestPrepareInputReencodesM4AForSpeechProfile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	workDir := filepath.Join(dir, "work")
+	runner := &fakeRunner{}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+	profile, _ := LookupProfile(ProfileSpeechOpus)
+
+	prepared, err := service.PrepareInput(context.Background(), input, workDir, InputOptions{Profile: profile})
+	if err != nil {
+		t.Fatalf("PrepareInput returned error: %v", err)
+	}
+
+	wantOutput := filepath.Join(workDir, "source.ogg")
+	if prepared.ChunkSourcePath != wantOutput || !prepared.Converted {
+		t.Fatalf("prepared = %#v, want converted %s", prepared, wantOutput)
+	}
+	wantArgs := []string{"-y", "-i", input, "-vn", "-ac", "1", "-ar", "16000", "-c:a", "libopus", "-b:a", "24k", "-application", "voip", "-fflags", "+bitexact", wantOutput}
 	if strings.Join(runner.calls[0].args, "\n") != strings.Join(wantArgs, "\n") {
 		t.Fatalf("args = %v, want %v", runner.calls[0].args, wantArgs)
 	}
 }
 
+func TestParseProfileNameKeepsEmptyForProviderDefault(t *testing.T) {
+	t.Parallel()
+
+	name, err := ParseProfileName("")
+	if err != nil || name != "" {
+		t.Fatalf("ParseProfileName(\"\") = %q, %v", name, err)
+	}
+	name, err = ParseProfileName(" Speech-FLAC ")
+	if err != nil || name != ProfileSpeechFLAC {
+		t.Fatalf("ParseProfileName = %q, %v", name, err)
+	}
+	if _, err := ParseProfileName("mp3"); err == nil {
+		t.Fatalf("expected unsupported profile error")
+	}
+}
+
 func TestPrepareInputPreservesFFmpegStderr(t *testing.T) {
 	t.Parallel()
 
@@ -165,7 +244,7 @@ func TestPrepareInputPreservesFFmpegStderr(t *testing.T) {
 	}
 	service := Service{FS: fsx.OS{}, Runner: runner}
 
-	_, err := service.PrepareInput(context.Background(), input, filepath.Join(dir, "work"))
+	_, err := service.PrepareInput(context.Background(), input, filepath.Join(dir, "work"), InputOptions{})
 	if err == nil {
 		t.Fatalf("expected PrepareInput error")
 	}
@@ -174,7 +253,7 @@ func TestPrepareInputPreservesFFmpegStderr(t *testing.T) {
 	}
 }
 
-func TestPrepareChunksWritesChunksInWorkDir(t *testing.T) {
+func TestStreamChunksWritesChunksInWorkDir(t *testing.T) {
 	t.Parallel()
 
 	dir := t.TempDir()
@@ -209,9 +288,9 @@ func TestPrepareChunksWritesChunksInWorkDir(t *testing.T) {
 	}
 	service := Service{FS: fsx.OS{}, Runner: runner}
 
-	chunks, err := service.PrepareChunks(context.Background(), filepath.Join(workDir, "source.m4a"), workDir, 600)
+	chunks, err := collectChunks(service, filepath.Join(workDir, "source.m4a"), workDir, ChunkOptions{Seconds: 600})
 	if err != nil {
-		t.Fatalf("PrepareChunks returned error: %v", err)
+		t.Fatalf("StreamChunks returned error: %v", err)
 	}
 
 	if len(chunks) != 2 {
@@ -238,7 +317,10 @@ func TestPrepareChunksWritesChunksInWorkDir(t *testing.T) {
 		"-i", filepath.Join(workDir, "source.m4a"),
 		"-f", "segment",
 		"-segment_time", "600",
+		"-segment_list", filepath.Join(workDir, "chunks.csv"),
+		"-segment_list_type", "csv",
 		"-c", "copy",
+		"-fflags", "+bitexact",
 		filepath.Join(workDir, "chunk_%03d.m4a"),
 	}
 	if strings.Join(runner.calls[0].args, "\n") != strings.Join(wantSplitArgs, "\n") {
@@ -246,7 +328,7 @@ func TestPrepareChunksWritesChunksInWorkDir(t *testing.T) {
 	}
 }
 
-func TestPrepareChunksPreservesFFmpegStderr(t *testing.T) {
+func TestStreamChunksPreservesFFmpegStderr(t *testing.T) {
 	t.Parallel()
 
 	dir := t.TempDir()
@@ -257,16 +339,16 @@ func TestPrepareChunksPreservesFFmpegStderr(t *testing.T) {
 	}
 	service := Service{FS: fsx.OS{}, Runner: runner}
 
-	_, err := service.PrepareChunks(context.Background(), filepath.Join(dir, "source.m4a"), filepath.Join(dir, "work"), 600)
+	_, err := collectChunks(service, filepath.Join(dir, "source.m4a"), filepath.Join(dir, "work"), ChunkOptions{Seconds: 600})
 	if err == nil {
-		t.Fatalf("expected PrepareChunks error")
+		t.Fatalf("expected StreamChunks error")
 	}
 	if !strings.Contains(err.Error(), "split audio") || !strings.Contains(err.Error(), "split failed") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
-func TestPrepareChunksPreservesFFprobeStderr(t *testing.T) {
+func TestStreamChunksPreservesFFprobeStderr(t *testing.T) {
 	t.Parallel()
 
 	dir := t.TempDir()
@@ -290,11 +372,968 @@ func TestPrepareChunksPreservesFFprobeStderr(t *testing.T) {
 	}
 	service := Service{FS: fsx.OS{}, Runner: runner}
 
-	_, err := service.PrepareChunks(context.Background(), filepath.Join(workDir, "source.m4a"), workDir, 600)
+	_, err := collectChunks(service, filepath.Join(workDir, "source.m4a"), workDir, ChunkOptions{Seconds: 600})
 	if err == nil {
-		t.Fatalf("expected PrepareChunks error")
+		t.Fatalf("expected StreamChunks error")
 	}
 	if !strings.Contains(err.Error(), "duration for") || !strings.Contains(err.Error(), "probe failed") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestParseSilencesReadsSilencedetectOutput(t *testing.T) {
+	t.Parallel()
+
+	output := []byte(strings.Join([]string{
+		"Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'source.m4a':",
+		"[silencedetect @ 0x55d] silence_start: -0.01",
+		"[silencedetect @ 0x55d] silence_end: 0.8 | silence_duration: 0.81",
+		"[silencedetect @ 0x55d] silence_start: 598.2",
+		"[silencedetect @ 0x55d] silence_end: 599.4 | silence_duration: 1.2",
+		"[silencedetect @ 0x55d] silence_start: 1190",
+	}, "\n"))
+
+	silences := parseSilences(output)
+	if len(silences) != 2 {
+		t.Fatalf("silences = %#v", silences)
+	}
+	if silences[0].Start != 0 || silences[0].End != 0.8 {
+		t.Fatalf("silences[0] = %#v", silences[0])
+	}
+	if silences[1].Start != 598.2 || silences[1].End != 599.4 {
+		t.Fatalf("silences[1] = %#v", silences[1])
+	}
+}
+
+func TestPlanCutsPrefersNearestPauseInsideTolerance(t *testing.T) {
+	t.Parallel()
+
+	silences := []silence{
+		{Start: 560, End: 561},   // outside the 30s window around 600
+		{Start: 590, End: 592},   // 9s before the target
+		{Start: 604, End: 606},   // 5s after the target: closest
+		{Start: 1250, End: 1252}, // 51s after the second target: too far
+	}
+
+	cuts := planCuts(1500, 600, 30, silences)
+	want := []float64{605, 1205}
+	if len(cuts) != len(want) {
+		t.Fatalf("cuts = %v, want %v", cuts, want)
+	}
+	for idx := range want {
+		if cuts[idx] != want[idx] {
+			t.Fatalf("cuts = %v, want %v", cuts, want)
+		}
+	}
+
+	if cuts := planCuts(500, 600, 30, silences); len(cuts) != 0 {
+		t.Fatalf("short input cuts = %v, want none", cuts)
+	}
+}
+
+func TestStreamChunksSilenceModeCutsAtPauses(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	source := filepath.Join(workDir, "source.m4a")
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, args ...string) ([]byte, []byte, error) {
+			switch {
+			case name == "ffprobe" && args[len(args)-1] == source:
+				return []byte("900\n"), nil, nil
+			case name == "ffprobe":
+				return []byte("500.02\n"), nil, nil
+			case name == "ffmpeg" && slices.Contains(args, "-af"):
+				return nil, []byte("[silencedetect @ 0x1] silence_start: 480\n[silencedetect @ 0x1] silence_end: 482.5 | silence_duration: 2.5\n"), nil
+			case name == "ffmpeg":
+				for _, file := range []string{"chunk_000.m4a", "chunk_001.m4a"} {
+					if err := os.WriteFile(filepath.Join(workDir, file), []byte("chunk"), 0o644); err != nil {
+						t.Fatalf("write chunk %s: %v", file, err)
+					}
+				}
+				return nil, nil, nil
+			}
+			t.Fatalf("unexpected command: %s %v", name, args)
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	chunks, err := collectChunks(service, source, workDir, ChunkOptions{
+		Seconds:          500,
+		Mode:             ChunkModeSilence,
+		SilenceTolerance: 30,
+	})
+	if err != nil {
+		t.Fatalf("StreamChunks returned error: %v", err)
+	}
+	if len(chunks) != 2 || chunks[1].Offset != 481.25 {
+		t.Fatalf("chunks = %#v", chunks)
+	}
+
+	splitArgs := runner.calls[2].args
+	if !strings.Contains(strings.Join(splitArgs, " "), "-segment_times 481.250") {
+		t.Fatalf("split args = %v", splitArgs)
+	}
+}
+
+func TestStreamChunksOverlapExtractsSharedWindows(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	source := filepath.Join(workDir, "source.m4a")
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, args ...string) ([]byte, []byte, error) {
+			if name == "ffprobe" {
+				return []byte("1250\n"), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	chunks, err := collectChunks(service, source, workDir, ChunkOptions{
+		Seconds: 600,
+		Overlap: 10,
+	})
+	if err != nil {
+		t.Fatalf("StreamChunks returned error: %v", err)
+	}
+
+	want := []Chunk{
+		{Number: 0, Offset: 0, Duration: 610},
+		{Number: 1, Offset: 600, Duration: 610, Overlap: 10},
+		{Number: 2, Offset: 1200, Duration: 50, Overlap: 10},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("chunks = %#v", chunks)
+	}
+	for idx := range want {
+		got := chunks[idx]
+		if got.Number != want[idx].Number || got.Offset != want[idx].Offset || got.Duration != want[idx].Duration || got.Overlap != want[idx].Overlap {
+			t.Fatalf("chunk[%d] = %#v, want %#v", idx, got, want[idx])
+		}
+	}
+
+	wantArgs := []string{"-y", "-ss", "600.000", "-t", "610.000", "-i", source, "-c", "copy", "-fflags", "+bitexact", filepath.Join(workDir, "chunk_001.m4a")}
+	if strings.Join(runner.calls[2].args, "\n") != strings.Join(wantArgs, "\n") {
+		t.Fatalf("extract args = %v, want %v", runner.calls[2].args, wantArgs)
+	}
+}
+
+func TestStreamChunksFitsProviderUploadLimit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		t.Fatalf("mkdir work dir: %v", err)
+	}
+	source := filepath.Join(workDir, "source.m4a")
+	if err := os.WriteFile(source, make([]byte, 10_000_000), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	sizes := map[string]int{
+		"chunk_000.m4a":     900_000,
+		"chunk_001.m4a":     1_200_000,
+		"chunk_001.fit.m4a": 950_000,
+	}
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, args ...string) ([]byte, []byte, error) {
+			switch name {
+			case "ffmpeg":
+				target := filepath.Base(args[len(args)-1])
+				files := []string{target}
+				if target == "chunk_%03d.m4a" {
+					files = []string{"chunk_000.m4a", "chunk_001.m4a"}
+				}
+				for _, file := range files {
+					if err := os.WriteFile(filepath.Join(workDir, file), make([]byte, sizes[file]), 0o644); err != nil {
+						t.Fatalf("write %s: %v", file, err)
+					}
+				}
+			case "ffprobe":
+				if filepath.Base(args[len(args)-1]) == "source.m4a" {
+					return []byte("1000\n"), nil, nil
+				}
+				return []byte("90\n"), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	chunks, err := collectChunks(service, source, workDir, ChunkOptions{
+		Seconds:  600,
+		MaxBytes: 1_000_000,
+	})
+	if err != nil {
+		t.Fatalf("StreamChunks returned error: %v", err)
+	}
+
+	if !slices.Contains(runner.calls[1].args, "90") {
+		t.Fatalf("split args = %v, want -segment_time 90", runner.calls[1].args)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("chunks = %#v", chunks)
+	}
+	if chunks[0].Path != filepath.Join(workDir, "chunk_000.m4a") {
+		t.Fatalf("chunk[0].Path = %s", chunks[0].Path)
+	}
+	if chunks[1].Path != filepath.Join(workDir, "chunk_001.fit.m4a") {
+		t.Fatalf("chunk[1].Path = %s, want re-encoded chunk", chunks[1].Path)
+	}
+	last := runner.calls[len(runner.calls)-1]
+	if !slices.Contains(last.args, "80000") {
+		t.Fatalf("re-encode args = %v, want -b:a 80000", last.args)
+	}
+}
+
+func TestStreamChunksFitsUploadLimitInAcceptedFormat(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		t.Fatalf("mkdir work dir: %v", err)
+	}
+	source := filepath.Join(workDir, "source.flac")
+	if err := os.WriteFile(source, make([]byte, 1_000_000), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	sizes := map[string]int{
+		"chunk_000.flac":    2_000_000,
+		"chunk_000.fit.ogg": 900_000,
+	}
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, args ...string) ([]byte, []byte, error) {
+			switch name {
+			case "ffmpeg":
+				target := filepath.Base(args[len(args)-1])
+				if target == "chunk_%03d.flac" {
+					target = "chunk_000.flac"
+				}
+				if err := os.WriteFile(filepath.Join(workDir, target), make([]byte, sizes[target]), 0o644); err != nil {
+					t.Fatalf("write %s: %v", target, err)
+				}
+			case "ffprobe":
+				return []byte("100\n"), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	chunks, err := collectChunks(service, source, workDir, ChunkOptions{
+		Seconds:       600,
+		MaxBytes:      1_000_000,
+		UploadFormats: []string{"flac", "wav", "ogg"},
+	})
+	if err != nil {
+		t.Fatalf("StreamChunks returned error: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Path != filepath.Join(workDir, "chunk_000.fit.ogg") {
+		t.Fatalf("chunks = %#v, want the chunk re-encoded to ogg", chunks)
+	}
+	last := runner.calls[len(runner.calls)-1]
+	if !slices.Contains(last.args, "libopus") {
+		t.Fatalf("re-encode args = %v, want libopus", last.args)
+	}
+
+	_, err = collectChunks(service, source, workDir, ChunkOptions{
+		Seconds:       600,
+		MaxBytes:      1_000_000,
+		UploadFormats: []string{"flac", "wav"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "none of the accepted formats (flac, wav)") {
+		t.Fatalf("expected an error about lossless-only formats, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(workDir, "chunk_000.fit.m4a")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no m4a upload for a flac-only provider, stat err = %v", statErr)
+	}
+}
+
+func TestPlanKeptSpansMapsCompactedTimeBack(t *testing.T) {
+	t.Parallel()
+
+	spans := planKeptSpans(100, 2, []silence{
+		{Start: 0, End: 5},
+		{Start: 20, End: 21},
+		{Start: 30, End: 40},
+		{Start: 95, End: 100},
+	})
+
+	want := TimeMap{
+		{Compact: 0, Original: 4.75, Duration: 25.5},
+		{Compact: 25.5, Original: 39.75, Duration: 55.5},
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("spans = %#v, want %#v", spans, want)
+	}
+	for idx := range want {
+		if spans[idx] != want[idx] {
+			t.Fatalf("span[%d] = %#v, want %#v", idx, spans[idx], want[idx])
+		}
+	}
+
+	if got := spans.Original(0); got != 4.75 {
+		t.Fatalf("Original(0) = %v, want 4.75", got)
+	}
+	if got := spans.Original(25.5); got != 39.75 {
+		t.Fatalf("Original(25.5) = %v, want 39.75", got)
+	}
+	if got := spans.OriginalEnd(25.5); got != 30.25 {
+		t.Fatalf("OriginalEnd(25.5) = %v, want 30.25", got)
+	}
+	if got := spans.Original(30); got != 44.25 {
+		t.Fatalf("Original(30) = %v, want 44.25", got)
+	}
+
+	if planKeptSpans(100, 2, []silence{{Start: 20, End: 21}}) != nil {
+		t.Fatalf("expected nil map when no silence is long enough")
+	}
+}
+
+func TestPrepareInputRemovesSilenceFromM4A(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	workDir := filepath.Join(dir, "work")
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, args ...string) ([]byte, []byte, error) {
+			if name == "ffprobe" {
+				return []byte("60\n"), nil, nil
+			}
+			if slices.Contains(args, "null") {
+				return nil, []byte("[silencedetect @ 0x1] silence_start: 10\n[silencedetect @ 0x1] silence_end: 20 | silence_duration: 10\n"), nil
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	prepared, err := service.PrepareInput(context.Background(), input, workDir, InputOptions{RemoveSilence: 3})
+	if err != nil {
+		t.Fatalf("PrepareInput returned error: %v", err)
+	}
+
+	if !prepared.Converted || prepared.ChunkSourcePath != filepath.Join(workDir, "source.m4a") {
+		t.Fatalf("prepared = %#v, want converted source", prepared)
+	}
+	if len(prepared.TimeMap) != 2 || prepared.TimeMap[1].Original != 19.75 {
+		t.Fatalf("TimeMap = %#v", prepared.TimeMap)
+	}
+	if !slices.Contains(runner.calls[1].args, "silencedetect=noise=-30dB:d=3") {
+		t.Fatalf("silencedetect args = %v", runner.calls[1].args)
+	}
+	wantFilter := "aselect='between(t,0.000,10.250)+between(t,19.750,60.000)',asetpts=N/SR/TB"
+	if !slices.Contains(runner.calls[2].args, wantFilter) {
+		t.Fatalf("convert args = %v, want filter %s", runner.calls[2].args, wantFilter)
+	}
+}
+
+func TestPrepareInputAppliesAtempo(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	workDir := filepath.Join(dir, "work")
+	runner := &fakeRunner{}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	prepared, err := service.PrepareInput(context.Background(), input, workDir, InputOptions{Speed: 1.5})
+	if err != nil {
+		t.Fatalf("PrepareInput returned error: %v", err)
+	}
+
+	if !prepared.Converted || prepared.Speed != 1.5 {
+		t.Fatalf("prepared = %#v, want converted at 1.5x", prepared)
+	}
+	wantArgs := []string{"-y", "-i", input, "-vn", "-af", "atempo=1.5", "-c:a", "aac", "-fflags", "+bitexact", filepath.Join(workDir, "source.m4a")}
+	if strings.Join(runner.calls[0].args, "\n") != strings.Join(wantArgs, "\n") {
+		t.Fatalf("args = %v, want %v", runner.calls[0].args, wantArgs)
+	}
+}
+
+func TestPrepareInputFiltersM4ABeforeAtempo(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	presets, err := ParseFilterPresets("highpass, loudnorm")
+	if err != nil {
+		t.Fatalf("ParseFilterPresets returned error: %v", err)
+	}
+	if _, err := ParseFilterPresets("echo"); err == nil {
+		t.Fatalf("expected unsupported preset error")
+	}
+
+	runner := &fakeRunner{}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	prepared, err := service.PrepareInput(context.Background(), input, filepath.Join(dir, "work"), InputOptions{
+		Speed:  2,
+		Filter: FilterChain(presets, "volume=2"),
+	})
+	if err != nil {
+		t.Fatalf("PrepareInput returned error: %v", err)
+	}
+
+	wantFilter := "highpass=f=80,loudnorm=I=-16:TP=-1.5:LRA=11,aresample=48000,volume=2"
+	if !prepared.Converted || prepared.Filter != wantFilter {
+		t.Fatalf("prepared = %#v, want converted with %s", prepared, wantFilter)
+	}
+	if !slices.Contains(runner.calls[0].args, wantFilter+",atempo=2") {
+		t.Fatalf("args = %v, want filter chain before atempo", runner.calls[0].args)
+	}
+}
+
+func TestParseAudioStreamsAndSelect(t *testing.T) {
+	t.Parallel()
+
+	streams, err := parseAudioStreams([]byte(`{"streams": [
+		{"index": 1, "codec_name": "aac", "channels": 2, "sample_rate": "48000", "tags": {"language": "rus"}},
+		{"index": 2, "codec_name": "aac", "channels": 2, "sample_rate": "48000", "tags": {"language": "eng", "title": "Commentary"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("parseAudioStreams returned error: %v", err)
+	}
+	if len(streams) != 2 || streams[1].Track != 1 || streams[1].Index != 2 || streams[1].Title != "Commentary" || streams[1].SampleRate != 48000 {
+		t.Fatalf("streams = %#v", streams)
+	}
+
+	stream, err := SelectAudioStream(streams, "ENG")
+	if err != nil || stream.Track != 1 {
+		t.Fatalf("SelectAudioStream(ENG) = %#v, %v", stream, err)
+	}
+	stream, err = SelectAudioStream(streams, "0")
+	if err != nil || stream.Language != "rus" {
+		t.Fatalf("SelectAudioStream(0) = %#v, %v", stream, err)
+	}
+	if _, err := SelectAudioStream(streams, "2"); err == nil {
+		t.Fatalf("expected missing track error")
+	}
+	if _, err := SelectAudioStream(streams, "deu"); err == nil {
+		t.Fatalf("expected missing language error")
+	}
+}
+
+func TestPrepareInputMapsSelectedAudioTrack(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "talk.m4a")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, _ ...string) ([]byte, []byte, error) {
+			if name == "ffprobe" {
+				return []byte(`{"streams": [{"index": 0, "tags": {"language": "rus"}}, {"index": 1, "tags": {"language": "eng"}}]}`), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	workDir := filepath.Join(dir, "work")
+	prepared, err := service.PrepareInput(context.Background(), input, workDir, InputOptions{AudioTrack: "eng"})
+	if err != nil {
+		t.Fatalf("PrepareInput returned error: %v", err)
+	}
+
+	if !prepared.Converted || prepared.AudioStream == nil || prepared.AudioStream.Track != 1 {
+		t.Fatalf("prepared = %#v, want converted track 1", prepared)
+	}
+	wantArgs := []string{"-y", "-i", input, "-map", "0:a:1", "-vn", "-c:a", "aac", "-fflags", "+bitexact", filepath.Join(workDir, "source.m4a")}
+	if strings.Join(runner.calls[1].args, "\n") != strings.Join(wantArgs, "\n") {
+		t.Fatalf("args = %v, want %v", runner.calls[1].args, wantArgs)
+	}
+}
+
+func TestPrepareInputSplitsChannels(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "call.wav")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, _ ...string) ([]byte, []byte, error) {
+			if name == "ffprobe" {
+				return []byte(`{"streams": [{"index": 0, "channels": 2}]}`), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+	profile, _ := LookupProfile(ProfileSpeechFLAC)
+
+	workDir := filepath.Join(dir, "work")
+	prepared, err := service.PrepareInput(context.Background(), input, workDir, InputOptions{Profile: profile, Channels: 2})
+	if err != nil {
+		t.Fatalf("PrepareInput returned error: %v", err)
+	}
+
+	wantPaths := []string{filepath.Join(workDir, "source_ch0.flac"), filepath.Join(workDir, "source_ch1.flac")}
+	if !slices.Equal(prepared.ChannelPaths, wantPaths) || prepared.ChunkSourcePath != wantPaths[0] {
+		t.Fatalf("prepared = %#v", prepared)
+	}
+	if !slices.Contains(runner.calls[2].args, "pan=mono|c0=c1") || !slices.Contains(runner.calls[2].args, "0:a:0") {
+		t.Fatalf("channel 1 args = %v", runner.calls[2].args)
+	}
+
+	if _, err := service.PrepareInput(context.Background(), input, workDir, InputOptions{Channels: 3}); err == nil || !strings.Contains(err.Error(), "has 2 channels") {
+		t.Fatalf("expected channel count error, got %v", err)
+	}
+}
+
+func TestParseTimeRanges(t *testing.T) {
+	t.Parallel()
+
+	ranges, err := ParseTimeRanges("10:00-20:00.5, 1:05:00-")
+	if err != nil {
+		t.Fatalf("ParseTimeRanges returned error: %v", err)
+	}
+	want := []TimeRange{{Start: 600, End: 1200.5}, {Start: 3900}}
+	if !slices.Equal(ranges, want) {
+		t.Fatalf("ranges = %#v, want %#v", ranges, want)
+	}
+
+	for _, value := range []string{"20:00-10:00", "0-60,30-90", "0-,60-90", "1:75-2:00", "10"} {
+		if _, err := ParseTimeRanges(value); err == nil {
+			t.Fatalf("ParseTimeRanges(%q) expected error", value)
+		}
+	}
+}
+
+func TestPrepareInputExtractsRanges(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "stream.mp4")
+	if err := os.WriteFile(input, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, _ ...string) ([]byte, []byte, error) {
+			if name == "ffprobe" {
+				return []byte("10800\n"), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+	workDir := filepath.Join(dir, "work")
+	output := filepath.Join(workDir, "source.m4a")
+
+	prepared, err := service.PrepareInput(context.Background(), input, workDir, InputOptions{
+		Ranges: []TimeRange{{Start: 600, End: 900}},
+	})
+	if err != nil {
+		t.Fatalf("PrepareInput returned error: %v", err)
+	}
+	if got := prepared.TimeMap.Original(12); got != 612 {
+		t.Fatalf("Original(12) = %v, want 612", got)
+	}
+	wantArgs := []string{"-y", "-ss", "600.000", "-to", "900.000", "-i", input, "-vn", "-c:a", "aac", "-fflags", "+bitexact", output}
+	if strings.Join(runner.calls[0].args, "\n") != strings.Join(wantArgs, "\n") {
+		t.Fatalf("single range args = %v, want %v", runner.calls[0].args, wantArgs)
+	}
+
+	runner.calls = nil
+	prepared, err = service.PrepareInput(context.Background(), input, workDir, InputOptions{
+		Ranges: []TimeRange{{Start: 600, End: 900}, {Start: 10000}},
+		Speed:  1.5,
+	})
+	if err != nil {
+		t.Fatalf("PrepareInput returned error: %v", err)
+	}
+	if got := prepared.TimeMap.Original(310); got != 10010 {
+		t.Fatalf("Original(310) = %v, want 10010", got)
+	}
+	last := runner.calls[len(runner.calls)-1]
+	wantArgs = []string{
+		"-y",
+		"-ss", "600.000", "-to", "900.000", "-i", input,
+		"-ss", "10000.000", "-i", input,
+		"-filter_complex", "[0:a][1:a]concat=n=2:v=0:a=1,atempo=1.5[out]",
+		"-map", "[out]",
+		"-c:a", "aac",
+		"-fflags", "+bitexact",
+		output,
+	}
+	if strings.Join(last.args, "\n") != strings.Join(wantArgs, "\n") {
+		t.Fatalf("multi range args = %v, want %v", last.args, wantArgs)
+	}
+}
+
+func TestCollectMediaFilesProbesUnknownExtensions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"call.amr", "recording", "broken.bin"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, _ string, args ...string) ([]byte, []byte, error) {
+			if filepath.Base(args[len(args)-1]) == "broken.bin" {
+				return nil, []byte("Invalid data found when processing input"), errors.New("exit status 1")
+			}
+			return []byte("audio\n"), nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	files, skipped, err := service.CollectMediaFiles(context.Background(), dir, ScanOptions{})
+	if err != nil {
+		t.Fatalf("CollectMediaFiles returned error: %v", err)
+	}
+
+	want := []string{filepath.Join(dir, "call.amr"), filepath.Join(dir, "recording")}
+	if !slices.Equal(files, want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+	if len(skipped) != 1 || skipped[0].Reason != "not a media file: Invalid data found when processing input" {
+		t.Fatalf("skipped = %#v", skipped)
+	}
+}
+
+func TestCollectMediaFilesRecursiveWithFilters(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	old := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for name, size := range map[string]int{
+		"top.mp3":                 100,
+		"talks/day1/keynote.m4a":  100,
+		"talks/day1/tiny.m4a":     5,
+		"talks/day1/old.m4a":      100,
+		"talks/drafts/draft.mp3":  100,
+		"talks/day1/notes.wav":    100,
+		"music/skip-this-one.mp3": 100,
+	} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("mkdir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(file, make([]byte, size), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := os.Chtimes(filepath.Join(dir, "talks", "day1", "old.m4a"), old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	service := Service{FS: fsx.OS{}, Runner: &fakeRunner{}}
+	files, _, err := service.CollectMediaFiles(context.Background(), dir, ScanOptions{
+		Recursive:     true,
+		Include:       []string{"*.m4a", "*.mp3"},
+		Exclude:       []string{"drafts", "music/*"},
+		MinSize:       10,
+		ModifiedSince: old.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("CollectMediaFiles returned error: %v", err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "talks", "day1", "keynote.m4a"),
+		filepath.Join(dir, "top.mp3"),
+	}
+	if !slices.Equal(files, want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+
+	files, _, err = service.CollectMediaFiles(context.Background(), dir, ScanOptions{})
+	if err != nil {
+		t.Fatalf("CollectMediaFiles returned error: %v", err)
+	}
+	if !slices.Equal(files, []string{filepath.Join(dir, "top.mp3")}) {
+		t.Fatalf("non-recursive files = %v", files)
+	}
+}
+
+func TestCollectMediaFilesSkipsOutputAndWorkDirectories(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "transcripts")
+	for _, name := range []string{
+		"talk.mp3",
+		"transcripts/talk/_work/chunk_000.mp3",
+		"transcripts/talk/talk.mp3",
+		"other/_work/chunk_000.mp3",
+		"other/interview.m4a",
+	} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("mkdir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(file, []byte("audio"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	service := Service{FS: fsx.OS{}, Runner: &fakeRunner{}}
+	files, _, err := service.CollectMediaFiles(context.Background(), dir, ScanOptions{Recursive: true, OutputDir: outputDir})
+	if err != nil {
+		t.Fatalf("CollectMediaFiles returned error: %v", err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "other", "interview.m4a"),
+		filepath.Join(dir, "talk.mp3"),
+	}
+	if !slices.Equal(files, want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+}
+
+func TestStreamChunksEmitsSegmentsBeforeSplitFinishes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	release := make(chan struct{})
+	runner := &fakeRunner{
+		runFunc: func(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+			switch name {
+			case "ffmpeg":
+				for _, file := range []string{"chunk_000.m4a", "chunk_001.m4a"} {
+					if err := os.WriteFile(filepath.Join(workDir, file), []byte("chunk"), 0o644); err != nil {
+						t.Errorf("write %s: %v", file, err)
+					}
+				}
+				// The muxer lists a segment as it closes it; chunk_001 is
+				// still being written.
+				if err := os.WriteFile(filepath.Join(workDir, "chunks.csv"), []byte("chunk_000.m4a,0.000000,600.000000\n"), 0o644); err != nil {
+					t.Errorf("write segment list: %v", err)
+				}
+				select {
+				case <-release:
+				case <-ctx.Done():
+					return nil, nil, ctx.Err()
+				}
+			case "ffprobe":
+				return []byte("600\n"), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	out := make(chan Chunk)
+	done := make(chan error, 1)
+	go func() {
+		done <- service.StreamChunks(context.Background(), filepath.Join(workDir, "source.m4a"), workDir, ChunkOptions{Seconds: 600}, out)
+	}()
+
+	select {
+	case chunk := <-out:
+		if chunk.Number != 0 || chunk.Offset != 0 {
+			t.Fatalf("first chunk = %#v", chunk)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("first chunk was not emitted while ffmpeg was still splitting")
+	}
+	close(release)
+
+	chunk, ok := <-out
+	if !ok || chunk.Number != 1 || chunk.Offset != 600 {
+		t.Fatalf("second chunk = %#v, ok = %v", chunk, ok)
+	}
+	if _, ok := <-out; ok {
+		t.Fatalf("expected the stream to close after the last chunk")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("StreamChunks returned error: %v", err)
+	}
+}
+
+func TestStreamChunksIgnoresChunksLeftByEarlierRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, file := range []string{"chunk_000.m4a", "chunk_001.m4a", "chunk_002.m4a", "chunk_003.m4a", "chunk_001.fit.m4a", "chunk_002.fit.ogg"} {
+		if err := os.WriteFile(filepath.Join(workDir, file), []byte("stale"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", file, err)
+		}
+	}
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, args ...string) ([]byte, []byte, error) {
+			switch name {
+			case "ffmpeg":
+				if err := os.WriteFile(filepath.Join(workDir, "chunk_000.m4a"), []byte("chunk"), 0o644); err != nil {
+					t.Errorf("write chunk: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(workDir, "chunks.csv"), []byte("chunk_000.m4a,0.000000,42.500000\n"), 0o644); err != nil {
+					t.Errorf("write segment list: %v", err)
+				}
+			case "ffprobe":
+				return []byte("600\n"), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	chunks, err := collectChunks(service, filepath.Join(dir, "source.m4a"), workDir, ChunkOptions{Seconds: 600})
+	if err != nil {
+		t.Fatalf("StreamChunks returned error: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Duration != 42.5 {
+		t.Fatalf("chunks = %#v, want the one segment ffmpeg wrote", chunks)
+	}
+	for _, file := range []string{"chunk_001.m4a", "chunk_003.m4a", "chunk_001.fit.m4a", "chunk_002.fit.ogg"} {
+		if _, err := os.Stat(filepath.Join(workDir, file)); !os.IsNotExist(err) {
+			t.Fatalf("expected stale %s to be removed, stat err = %v", file, err)
+		}
+	}
+}
+
+func TestStreamChunksRereadsSegmentListWhenSplitFinishes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, args ...string) ([]byte, []byte, error) {
+			if name != "ffmpeg" {
+				return []byte("600\n"), nil, nil
+			}
+			for _, file := range []string{"chunk_000.m4a", "chunk_001.m4a"} {
+				if err := os.WriteFile(filepath.Join(workDir, file), []byte("chunk"), 0o644); err != nil {
+					t.Errorf("write %s: %v", file, err)
+				}
+			}
+			// Let the stream poll the empty list before ffmpeg flushes it
+			// on exit.
+			time.Sleep(2 * segmentPollInterval)
+			list := "chunk_000.m4a,0.000000,599.980000\nchunk_001.m4a,599.980000,900.500000\n"
+			if err := os.WriteFile(filepath.Join(workDir, "chunks.csv"), []byte(list), 0o644); err != nil {
+				t.Errorf("write segment list: %v", err)
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	chunks, err := collectChunks(service, filepath.Join(dir, "source.m4a"), workDir, ChunkOptions{Seconds: 600})
+	if err != nil {
+		t.Fatalf("StreamChunks returned error: %v", err)
+	}
+	if len(chunks) != 2 || chunks[1].Offset != 599.98 || chunks[1].Duration != 900.5-599.98 {
+		t.Fatalf("chunks = %#v", chunks)
+	}
+	for _, call := range runner.calls {
+		if call.name == "ffprobe" {
+			t.Fatalf("expected offsets from the segment list without ffprobe, got %v", call.args)
+		}
+	}
+}
+
+func TestStreamChunksTakesOffsetsFromSegmentListWithoutDrift(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	const (
+		chunkCount = 120
+		// Stream copy cuts on packet boundaries, so every segment runs a
+		// little past the requested 600 seconds, while ffprobe reports a
+		// rounded duration. Summing those durations drifts by ~1.4s here.
+		segmentLength = 600.0235
+		probedLength  = "600.012"
+	)
+	runner := &fakeRunner{
+		runFunc: func(_ context.Context, name string, args ...string) ([]byte, []byte, error) {
+			switch name {
+			case "ffmpeg":
+				var list strings.Builder
+				for index := 0; index < chunkCount; index++ {
+					file := fmt.Sprintf("chunk_%03d.m4a", index)
+					if err := os.WriteFile(filepath.Join(workDir, file), []byte("chunk"), 0o644); err != nil {
+						t.Errorf("write %s: %v", file, err)
+					}
+					// The last entry is missing, as if ffmpeg was killed
+					// before closing the list; it falls back to ffprobe.
+					if index < chunkCount-1 {
+						start := float64(index) * segmentLength
+						fmt.Fprintf(&list, "%s,%.6f,%.6f\n", file, start, start+segmentLength)
+					}
+				}
+				if err := os.WriteFile(filepath.Join(workDir, "chunks.csv"), []byte(list.String()), 0o644); err != nil {
+					t.Errorf("write segment list: %v", err)
+				}
+			case "ffprobe":
+				return []byte(probedLength + "\n"), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+	service := Service{FS: fsx.OS{}, Runner: runner}
+
+	chunks, err := collectChunks(service, filepath.Join(workDir, "source.m4a"), workDir, ChunkOptions{Seconds: 600})
+	if err != nil {
+		t.Fatalf("StreamChunks returned error: %v", err)
+	}
+	if len(chunks) != chunkCount {
+		t.Fatalf("chunk count = %d, want %d", len(chunks), chunkCount)
+	}
+	for _, chunk := range chunks[:chunkCount-1] {
+		want := float64(chunk.Number) * segmentLength
+		if math.Abs(chunk.Offset-want) > 1e-6 {
+			t.Fatalf("chunk %d offset = %f, want %f", chunk.Number, chunk.Offset, want)
+		}
+	}
+	last := chunks[chunkCount-1]
+	if want := float64(chunkCount-1) * segmentLength; math.Abs(last.Offset-want) > 1e-6 || last.Duration != 600.012 {
+		t.Fatalf("fallback chunk = %#v, want offset %f", last, want)
+	}
+
+	probes := 0
+	for _, call := range runner.calls {
+		if call.name == "ffprobe" {
+			probes++
+		}
+	}
+	if probes != 1 {
+		t.Fatalf("ffprobe calls = %d, want 1 for the unlisted chunk", probes)
+	}
+}
+
+func TestParseSegmentListStopsAtPartialLine(t *testing.T) {
+	t.Parallel()
+
+	entries := parseSegmentList([]byte("chunk_000.m4a,0.000000,600.023000\n\"a,b.m4a\",600.023000,1200.04\nchunk_002.m4a,1200.04"))
+	if len(entries) != 2 || entries[1].Start != 600.023 || entries[1].End != 1200.04 {
+		t.Fatalf("entries = %#v", entries)
+	}
+}
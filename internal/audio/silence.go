@@ -0,0 +1,164 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	silenceNoise = "-30dB"
+	// chunkSilenceDuration is the shortest pause a silence-aware cut may use.
+	chunkSilenceDuration = 0.5
+)
+
+type silence struct {
+	Start float64
+	End   float64
+}
+
+func (s silence) midpoint() float64 {
+	return (s.Start + s.End) / 2
+}
+
+func (s Service) streamSilenceChunks(ctx context.Context, inputFile string, outputPattern string, opts ChunkOptions, emit func(Chunk) error) error {
+	total, err := s.duration(ctx, inputFile)
+	if err != nil {
+		return fmt.Errorf("duration for %s: %w", inputFile, err)
+	}
+
+	silences, err := s.detectSilences(ctx, inputFile, chunkSilenceDuration, nil)
+	if err != nil {
+		return err
+	}
+
+	cuts := planCuts(total, float64(opts.Seconds), opts.SilenceTolerance, silences)
+	listPath := segmentListPath(outputPattern)
+	if len(cuts) == 0 {
+		return s.streamSegments(ctx, outputPattern, listPath, nil, func(ctx context.Context) error {
+			return s.split(ctx, inputFile, outputPattern, listPath, opts.Seconds)
+		}, emit)
+	}
+	return s.streamSegments(ctx, outputPattern, listPath, cuts, func(ctx context.Context) error {
+		return s.splitAt(ctx, inputFile, outputPattern, listPath, cuts)
+	}, emit)
+}
+
+func (s Service) detectSilences(ctx context.Context, inputFile string, minDuration float64, mapArgs []string) ([]silence, error) {
+	args := []string{"-hide_banner", "-nostats", "-i", inputFile}
+	args = append(args, mapArgs...)
+	args = append(args,
+		"-af", "silencedetect=noise="+silenceNoise+":d="+strconv.FormatFloat(minDuration, 'f', -1, 64),
+		"-f", "null",
+		"-",
+	)
+	_, stderr, err := s.Runner.Run(ctx, "ffmpeg", args...)
+	if err != nil {
+		return nil, fmt.Errorf("detect silence: %w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+	return parseSilences(stderr), nil
+}
+
+// parseSilences extracts silence intervals from ffmpeg silencedetect output:
+//
+//	[silencedetect @ 0x...] silence_start: 12.34
+//	[silencedetect @ 0x...] silence_end: 13.5 | silence_duration: 1.16
+func parseSilences(output []byte) []silence {
+	var (
+		result  []silence
+		start   float64
+		started bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := silenceValue(line, "silence_start:"); ok {
+			start = math.Max(value, 0)
+			started = true
+			continue
+		}
+		if value, ok := silenceValue(line, "silence_end:"); ok && started {
+			result = append(result, silence{Start: start, End: value})
+			started = false
+		}
+	}
+	return result
+}
+
+func silenceValue(line string, marker string) (float64, bool) {
+	_, rest, ok := strings.Cut(line, marker)
+	if !ok {
+		return 0, false
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// planCuts returns cut points roughly every target seconds, moving each cut
+// to the pause closest to the target inside the tolerance window. Cuts fall
+// back to the exact target when no pause is close enough.
+func planCuts(total float64, target float64, tolerance float64, silences []silence) []float64 {
+	if target <= 0 || total <= target {
+		return nil
+	}
+	tolerance = math.Min(math.Max(tolerance, 0), target/2)
+
+	var (
+		cuts     []float64
+		position float64
+	)
+	for total-position > target {
+		desired := position + target
+		cut := desired
+		best := math.Inf(1)
+		for _, item := range silences {
+			mid := item.midpoint()
+			distance := math.Abs(mid - desired)
+			if distance <= tolerance && distance < best && mid > position && mid < total {
+				cut = mid
+				best = distance
+			}
+		}
+		// Round to the millisecond precision passed to -segment_times.
+		cut = math.Round(cut*1000) / 1000
+		cuts = append(cuts, cut)
+		position = cut
+	}
+	return cuts
+}
+
+func (s Service) splitAt(ctx context.Context, inputPath string, outputPattern string, listPath string, cuts []float64) error {
+	times := make([]string, 0, len(cuts))
+	for _, cut := range cuts {
+		times = append(times, strconv.FormatFloat(cut, 'f', 3, 64))
+	}
+
+	args := []string{
+		"-y",
+		"-i", inputPath,
+		"-f", "segment",
+		"-segment_times", strings.Join(times, ","),
+	}
+	args = append(args, segmentListArgs(listPath)...)
+	args = append(args, "-c", "copy")
+	args = append(args, bitexactArgs...)
+	args = append(args, outputPattern)
+
+	_, stderr, err := s.Runner.Run(ctx, "ffmpeg", args...)
+	if err != nil {
+		return fmt.Errorf("split audio: %w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// segmentPollInterval is how often the work directory is checked for the
+// next segment while ffmpeg is still splitting.
+const segmentPollInterval = 100 * time.Millisecond
+
+// StreamChunks cuts inputFile into chunks in workDir and sends every chunk
+// on out, in order, as soon as its file is complete, so uploads can start
+// while ffmpeg is still working through the rest of the input. out is
+// closed when StreamChunks returns.
+func (s Service) StreamChunks(ctx context.Context, inputFile string, workDir string, opts ChunkOptions, out chan<- Chunk) error {
+	defer close(out)
+
+	if opts.Seconds <= 0 {
+		return fmt.Errorf("chunk_seconds must be greater than zero")
+	}
+
+	outputPattern := filepath.Join(workDir, "chunk_%03d"+extension(inputFile))
+	if err := s.FS.MkdirAll(workDir, 0o755); err != nil {
+		return fmt.Errorf("create work directory: %w", err)
+	}
+	if err := s.removeChunks(outputPattern); err != nil {
+		return err
+	}
+
+	if opts.MaxBytes > 0 {
+		seconds, err := s.fitChunkSeconds(ctx, inputFile, opts.Seconds, opts.MaxBytes)
+		if err != nil {
+			return err
+		}
+		opts.Seconds = seconds
+		opts.Overlap = min(opts.Overlap, float64(seconds)/2)
+	}
+
+	emit := func(chunk Chunk) error {
+		if opts.MaxBytes > 0 {
+			var err error
+			if chunk, err = s.fitChunk(ctx, chunk, opts.MaxBytes, opts.UploadFormats); err != nil {
+				return err
+			}
+		}
+		select {
+		case out <- chunk:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	switch {
+	case opts.Overlap > 0:
+		return s.streamOverlapChunks(ctx, inputFile, outputPattern, opts, emit)
+	case opts.Mode == ChunkModeSilence:
+		return s.streamSilenceChunks(ctx, inputFile, outputPattern, opts, emit)
+	default:
+		listPath := segmentListPath(outputPattern)
+		return s.streamSegments(ctx, outputPattern, listPath, nil, func(ctx context.Context) error {
+			return s.split(ctx, inputFile, outputPattern, listPath, opts.Seconds)
+		}, emit)
+	}
+}
+
+// streamSegments runs an ffmpeg segment muxer in the background and emits
+// each segment once it is complete. The muxer appends a line to listPath as
+// it closes every segment, and that line carries the exact start and end
+// time of the segment, so it is both the completion signal and the offset
+// source. When a segment has no list entry, it counts as complete only once
+// ffmpeg has exited, and its timing falls back to cuts or to ffprobe
+// durations summed from the previous segment.
+func (s Service) streamSegments(ctx context.Context, outputPattern string, listPath string, cuts []float64, run func(context.Context) error, emit func(Chunk) error) error {
+	// Truncate a list left behind by an earlier run before polling it.
+	if err := s.FS.WriteFile(listPath, nil, 0o644); err != nil {
+		return fmt.Errorf("reset segment list: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- run(runCtx)
+	}()
+	finished := false
+	defer func() {
+		cancel()
+		if !finished {
+			<-done
+		}
+	}()
+
+	var (
+		entries []segmentEntry
+		offset  float64
+	)
+	for index := 0; ; {
+		chunkFile := fmt.Sprintf(outputPattern, index)
+		if !finished && len(entries) <= index {
+			entries = s.readSegmentList(listPath)
+		}
+		listed := index < len(entries)
+		if !finished && !listed {
+			select {
+			case err := <-done:
+				finished = true
+				if err != nil {
+					return err
+				}
+				// ffmpeg writes the last lines of the list on exit; read
+				// it once more so only segments it never listed fall
+				// back to ffprobe.
+				entries = s.readSegmentList(listPath)
+			case <-time.After(segmentPollInterval):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+		if !listed && finished && !s.exists(chunkFile) {
+			if index == 0 {
+				return fmt.Errorf("no chunks generated for %s", outputPattern)
+			}
+			return nil
+		}
+
+		chunk := Chunk{Number: index, Path: chunkFile}
+		if listed {
+			chunk.Offset = entries[index].Start
+			chunk.Duration = entries[index].End - entries[index].Start
+		} else {
+			duration, err := s.duration(ctx, chunkFile)
+			if err != nil {
+				return fmt.Errorf("duration for %s: %w", chunkFile, err)
+			}
+			chunk.Offset = offset
+			if index > 0 && index-1 < len(cuts) {
+				chunk.Offset = cuts[index-1]
+			}
+			chunk.Duration = duration
+		}
+		if err := emit(chunk); err != nil {
+			return err
+		}
+		offset = chunk.Offset + chunk.Duration
+		index++
+	}
+}
+
+// removeChunks deletes chunk files of outputPattern, fitted copies
+// included, that an earlier run left in the work directory, so they are not
+// taken for segments of this one.
+func (s Service) removeChunks(outputPattern string) error {
+	name := strings.Replace(filepath.Base(outputPattern), "%03d", "*", 1)
+	fitted := fittedChunkPath(name, "*")
+	entries, err := s.FS.ReadDir(filepath.Dir(outputPattern))
+	if err != nil {
+		return fmt.Errorf("read work directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		chunk, _ := filepath.Match(name, entry.Name())
+		fit, _ := filepath.Match(fitted, entry.Name())
+		if !chunk && !fit {
+			continue
+		}
+		path := filepath.Join(filepath.Dir(outputPattern), entry.Name())
+		if err := s.FS.Remove(path); err != nil {
+			return fmt.Errorf("remove stale chunk %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (s Service) exists(path string) bool {
+	_, err := s.FS.Stat(path)
+	return err == nil
+}
+
+// segmentListPath is where the segment muxer records the chunks of
+// outputPattern.
+func segmentListPath(outputPattern string) string {
+	return filepath.Join(filepath.Dir(outputPattern), "chunks.csv")
+}
+
+// segmentListArgs makes the segment muxer record every closed segment.
+func segmentListArgs(listPath string) []string {
+	return []string{"-segment_list", listPath, "-segment_list_type", "csv"}
+}
+
+type segmentEntry struct {
+	Start float64
+	End   float64
+}
+
+// readSegmentList returns the entries written to listPath so far; a missing
+// or unreadable list has no entries.
+func (s Service) readSegmentList(listPath string) []segmentEntry {
+	data, err := s.FS.ReadFile(listPath)
+	if err != nil {
+		return nil
+	}
+	return parseSegmentList(data)
+}
+
+// parseSegmentList reads "name,start,end" lines of an ffmpeg csv segment
+// list. It stops at the first line that is incomplete or malformed, since
+// ffmpeg may be in the middle of writing it.
+func parseSegmentList(data []byte) []segmentEntry {
+	var entries []segmentEntry
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			break
+		}
+		line := strings.TrimSpace(string(data[:end]))
+		data = data[end+1:]
+
+		// The file name may be quoted and contain commas; the times are
+		// always the last two fields.
+		fields := strings.Split(line, ",")
+		if len(fields) < 3 {
+			break
+		}
+		start, startErr := strconv.ParseFloat(fields[len(fields)-2], 64)
+		stop, stopErr := strconv.ParseFloat(fields[len(fields)-1], 64)
+		if startErr != nil || stopErr != nil || stop < start {
+			break
+		}
+		entries = append(entries, segmentEntry{Start: start, End: stop})
+	}
+	return entries
+}
@@ -0,0 +1,94 @@
+package audio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AudioStream describes one audio stream of a media file.
+type AudioStream struct {
+	// Track is the position among audio streams, as used by -map 0:a:N.
+	Track      int    `json:"track"`
+	Index      int    `json:"index"`
+	Codec      string `json:"codec,omitempty"`
+	Channels   int    `json:"channels,omitempty"`
+	SampleRate int    `json:"sample_rate,omitempty"`
+	Language   string `json:"language,omitempty"`
+	Title      string `json:"title,omitempty"`
+}
+
+func (s AudioStream) mapArgs() []string {
+	return []string{"-map", "0:a:" + strconv.Itoa(s.Track)}
+}
+
+type probeStreams struct {
+	Streams []struct {
+		Index      int    `json:"index"`
+		CodecName  string `json:"codec_name"`
+		Channels   int    `json:"channels"`
+		SampleRate string `json:"sample_rate"`
+		Tags       struct {
+			Language string `json:"language"`
+			Title    string `json:"title"`
+		} `json:"tags"`
+	} `json:"streams"`
+}
+
+func (s Service) ListAudioStreams(ctx context.Context, inputFile string) ([]AudioStream, error) {
+	stdout, stderr, err := s.Runner.Run(
+		ctx,
+		"ffprobe",
+		"-v", "error",
+		"-select_streams", "a",
+		"-show_entries", "stream=index,codec_name,channels,sample_rate:stream_tags=language,title",
+		"-of", "json",
+		inputFile,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe: %w: %s", err, strings.TrimSpace(string(stderr)))
+	}
+	return parseAudioStreams(stdout)
+}
+
+func parseAudioStreams(data []byte) ([]AudioStream, error) {
+	var probe probeStreams
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("parse ffprobe streams: %w", err)
+	}
+
+	streams := make([]AudioStream, 0, len(probe.Streams))
+	for track, item := range probe.Streams {
+		sampleRate, _ := strconv.Atoi(item.SampleRate)
+		streams = append(streams, AudioStream{
+			Track:      track,
+			Index:      item.Index,
+			Codec:      item.CodecName,
+			Channels:   item.Channels,
+			SampleRate: sampleRate,
+			Language:   item.Tags.Language,
+			Title:      item.Tags.Title,
+		})
+	}
+	return streams, nil
+}
+
+// SelectAudioStream picks a stream by audio track number or by language tag.
+func SelectAudioStream(streams []AudioStream, selector string) (AudioStream, error) {
+	selector = strings.TrimSpace(selector)
+	if track, err := strconv.Atoi(selector); err == nil {
+		if track < 0 || track >= len(streams) {
+			return AudioStream{}, fmt.Errorf("audio track %d not found; input has %d audio streams", track, len(streams))
+		}
+		return streams[track], nil
+	}
+
+	for _, stream := range streams {
+		if strings.EqualFold(stream.Language, selector) {
+			return stream, nil
+		}
+	}
+	return AudioStream{}, fmt.Errorf("no audio track with language %q", selector)
+}
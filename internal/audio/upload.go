@@ -0,0 +1,140 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// uploadSafetyMargin leaves room for container overhead and bitrate peaks.
+	uploadSafetyMargin = 0.9
+	// minFitBitrate is the lowest bitrate a chunk is re-encoded to.
+	minFitBitrate = 16_000
+)
+
+// fitChunkSeconds shortens chunkSeconds so that chunks of the input, at its
+// average bitrate, stay below maxBytes.
+func (s Service) fitChunkSeconds(ctx context.Context, inputFile string, chunkSeconds int, maxBytes int64) (int, error) {
+	info, err := s.FS.Stat(inputFile)
+	if err != nil {
+		return 0, fmt.Errorf("stat %s: %w", inputFile, err)
+	}
+	total, err := s.duration(ctx, inputFile)
+	if err != nil {
+		return 0, fmt.Errorf("duration for %s: %w", inputFile, err)
+	}
+	if total <= 0 || info.Size() <= 0 {
+		return chunkSeconds, nil
+	}
+
+	bytesPerSecond := float64(info.Size()) / total
+	safe := int(math.Floor(float64(maxBytes) * uploadSafetyMargin / bytesPerSecond))
+	if safe < 1 {
+		safe = 1
+	}
+	return min(chunkSeconds, safe), nil
+}
+
+// fitEncoding is a container and lossy codec a chunk can be re-encoded to
+// at a chosen bitrate.
+type fitEncoding struct {
+	format string
+	codec  string
+}
+
+// fitEncodings are tried in order; lossless formats such as flac and wav
+// cannot trade quality for size and are left out.
+var fitEncodings = []fitEncoding{
+	{format: "m4a", codec: "aac"},
+	{format: "ogg", codec: "libopus"},
+	{format: "webm", codec: "libopus"},
+	{format: "mp3", codec: "libmp3lame"},
+}
+
+// fitEncodingFor picks the encoding for an oversized chunk: its own format
+// when that can be re-encoded, otherwise the first one accepted. accepted
+// lists extensions without the dot; an empty list accepts any format.
+func fitEncodingFor(chunkPath string, accepted []string) (fitEncoding, bool) {
+	allowed := func(format string) bool {
+		return len(accepted) == 0 || slices.Contains(accepted, format)
+	}
+	own := strings.TrimPrefix(extension(chunkPath), ".")
+	for _, encoding := range fitEncodings {
+		if encoding.format == own && allowed(own) {
+			return encoding, true
+		}
+	}
+	for _, encoding := range fitEncodings {
+		if allowed(encoding.format) {
+			return encoding, true
+		}
+	}
+	return fitEncoding{}, false
+}
+
+// fitChunk re-encodes a chunk that is still larger than maxBytes at a
+// bitrate that fits, in a format from accepted, so no request is sent that
+// the provider would reject.
+func (s Service) fitChunk(ctx context.Context, chunk Chunk, maxBytes int64, accepted []string) (Chunk, error) {
+	info, err := s.FS.Stat(chunk.Path)
+	if err != nil {
+		return Chunk{}, fmt.Errorf("stat %s: %w", chunk.Path, err)
+	}
+	if info.Size() <= maxBytes {
+		return chunk, nil
+	}
+	if chunk.Duration <= 0 {
+		return Chunk{}, fmt.Errorf("chunk %s is %d bytes, over the %d byte upload limit", chunk.Path, info.Size(), maxBytes)
+	}
+	encoding, ok := fitEncodingFor(chunk.Path, accepted)
+	if !ok {
+		return Chunk{}, fmt.Errorf("chunk %s is %d bytes, over the %d byte upload limit, and none of the accepted formats (%s) can be re-encoded to a lower bitrate; lower --chunk-seconds", chunk.Path, info.Size(), maxBytes, strings.Join(accepted, ", "))
+	}
+
+	bitrate := int(float64(maxBytes) * 8 * uploadSafetyMargin / chunk.Duration)
+	if bitrate < minFitBitrate {
+		return Chunk{}, fmt.Errorf("chunk %s cannot fit the %d byte upload limit; lower --chunk-seconds", chunk.Path, maxBytes)
+	}
+
+	fittedPath := fittedChunkPath(chunk.Path, encoding.format)
+	if err := s.reencode(ctx, chunk.Path, fittedPath, encoding.codec, bitrate); err != nil {
+		return Chunk{}, err
+	}
+	fitted, err := s.FS.Stat(fittedPath)
+	if err != nil {
+		return Chunk{}, fmt.Errorf("stat %s: %w", fittedPath, err)
+	}
+	if fitted.Size() > maxBytes {
+		return Chunk{}, fmt.Errorf("chunk %s is %d bytes after re-encoding, over the %d byte upload limit", fittedPath, fitted.Size(), maxBytes)
+	}
+	chunk.Path = fittedPath
+	return chunk, nil
+}
+
+// fittedChunkPath is where the re-encoded copy of chunkPath is written.
+func fittedChunkPath(chunkPath string, format string) string {
+	return strings.TrimSuffix(chunkPath, filepath.Ext(chunkPath)) + ".fit." + format
+}
+
+func (s Service) reencode(ctx context.Context, inputPath string, outputPath string, codec string, bitrate int) error {
+	args := []string{
+		"-y",
+		"-i", inputPath,
+		"-ac", "1",
+		"-c:a", codec,
+		"-b:a", strconv.Itoa(bitrate),
+	}
+	args = append(args, bitexactArgs...)
+	args = append(args, outputPath)
+
+	_, stderr, err := s.Runner.Run(ctx, "ffmpeg", args...)
+	if err != nil {
+		return fmt.Errorf("re-encode chunk %s: %w: %s", inputPath, err, strings.TrimSpace(string(stderr)))
+	}
+	return nil
+}
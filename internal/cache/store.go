@@ -0,0 +1,173 @@
+// Package cache stores provider results by content, so the same audio sent
+// with the same settings is transcribed once across runs and output
+// directories.
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+)
+
+const entryExt = ".json"
+
+// Entry is one cached provider result: the normalised transcript of a chunk
+// on the chunk timeline and the raw response, if one was requested.
+type Entry struct {
+	Transcript domain.Transcript `json:"transcript"`
+	Raw        []byte            `json:"raw,omitempty"`
+}
+
+// Store keeps entries under Dir, sharded by the first two characters of
+// the key.
+type Store struct {
+	FS  fsx.FS
+	Dir string
+}
+
+// Stats summarises the entries of a store.
+type Stats struct {
+	Entries int
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// DefaultDir returns $XDG_CACHE_HOME/whisper-cli, falling back to
+// $HOME/.cache/whisper-cli.
+func DefaultDir(lookup func(string) (string, bool)) (string, error) {
+	if dir, ok := lookup("XDG_CACHE_HOME"); ok && strings.TrimSpace(dir) != "" {
+		return filepath.Join(strings.TrimSpace(dir), "whisper-cli"), nil
+	}
+	if home, ok := lookup("HOME"); ok && strings.TrimSpace(home) != "" {
+		return filepath.Join(strings.TrimSpace(home), ".cache", "whisper-cli"), nil
+	}
+	return "", errors.New("cannot locate cache directory; set XDG_CACHE_HOME, HOME or WHISPER_CLI_CACHE_DIR")
+}
+
+func (s Store) path(key string) string {
+	return filepath.Join(s.Dir, key[:2], key+entryExt)
+}
+
+// Get returns the entry for key. Unreadable or truncated entries count as
+// missing.
+func (s Store) Get(key string) (Entry, bool) {
+	if len(key) < 2 {
+		return Entry{}, false
+	}
+	data, err := s.FS.ReadFile(s.path(key))
+	if err != nil {
+		return Entry{}, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Put stores entry under key, replacing an earlier entry.
+func (s Store) Put(key string, entry Entry) error {
+	if len(key) < 2 {
+		return fmt.Errorf("invalid cache key %q", key)
+	}
+	path := s.path(key)
+	if err := s.FS.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode cache entry: %w", err)
+	}
+	if err := s.FS.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Stats counts the entries and their size on disk.
+func (s Store) Stats() (Stats, error) {
+	var stats Stats
+	err := s.walk(func(_ string, info os.FileInfo) error {
+		stats.Entries++
+		stats.Bytes += info.Size()
+		if stats.Oldest.IsZero() || info.ModTime().Before(stats.Oldest) {
+			stats.Oldest = info.ModTime()
+		}
+		if info.ModTime().After(stats.Newest) {
+			stats.Newest = info.ModTime()
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// Prune removes entries written before cutoff and reports how many were
+// removed.
+func (s Store) Prune(cutoff time.Time) (int, error) {
+	return s.removeWhere(func(info os.FileInfo) bool {
+		return info.ModTime().Before(cutoff)
+	})
+}
+
+// Clear removes every entry and reports how many were removed.
+func (s Store) Clear() (int, error) {
+	return s.removeWhere(func(os.FileInfo) bool { return true })
+}
+
+func (s Store) removeWhere(match func(os.FileInfo) bool) (int, error) {
+	removed := 0
+	err := s.walk(func(path string, info os.FileInfo) error {
+		if !match(info) {
+			return nil
+		}
+		if err := s.FS.Remove(path); err != nil {
+			return fmt.Errorf("remove cache entry: %w", err)
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+// walk visits every entry file; a store that does not exist yet is empty.
+func (s Store) walk(visit func(path string, info os.FileInfo) error) error {
+	shards, err := s.FS.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read cache directory: %w", err)
+	}
+
+	for _, shard := range shards {
+		if !shard.IsDir() {
+			continue
+		}
+		shardDir := filepath.Join(s.Dir, shard.Name())
+		entries, err := s.FS.ReadDir(shardDir)
+		if err != nil {
+			return fmt.Errorf("read cache directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != entryExt {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return fmt.Errorf("stat cache entry: %w", err)
+			}
+			if err := visit(filepath.Join(shardDir, entry.Name()), info); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
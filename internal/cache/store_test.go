@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+)
+
+func TestStorePutGetStatsPruneClear(t *testing.T) {
+	t.Parallel()
+
+	store := Store{FS: fsx.OS{}, Dir: filepath.Join(t.TempDir(), "cache")}
+	if stats, err := store.Stats(); err != nil || stats.Entries != 0 {
+		t.Fatalf("stats of missing store = %#v, %v", stats, err)
+	}
+
+	for _, key := range []string{"aa01", "aa02", "bb03"} {
+		entry := Entry{Transcript: domain.Transcript{Text: key}, Raw: []byte(`{"text":"` + key + `"}`)}
+		if err := store.Put(key, entry); err != nil {
+			t.Fatalf("Put(%s) returned error: %v", key, err)
+		}
+	}
+	entry, ok := store.Get("bb03")
+	if !ok || entry.Transcript.Text != "bb03" || string(entry.Raw) != `{"text":"bb03"}` {
+		t.Fatalf("Get = %#v, %v", entry, ok)
+	}
+	if _, ok := store.Get("cc04"); ok {
+		t.Fatalf("expected a miss for an unknown key")
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(filepath.Join(store.Dir, "aa", "aa01.json"), old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats returned error: %v", err)
+	}
+	if stats.Entries != 3 || stats.Bytes == 0 || !stats.Oldest.Equal(old) {
+		t.Fatalf("stats = %#v", stats)
+	}
+
+	removed, err := store.Prune(time.Now().Add(-24 * time.Hour))
+	if err != nil || removed != 1 {
+		t.Fatalf("Prune = %d, %v; want 1 removed", removed, err)
+	}
+	if _, ok := store.Get("aa01"); ok {
+		t.Fatalf("expected pruned entry to be gone")
+	}
+
+	removed, err = store.Clear()
+	if err != nil || removed != 2 {
+		t.Fatalf("Clear = %d, %v; want 2 removed", removed, err)
+	}
+}
+
+func TestDefaultDirPrefersXDGCacheHome(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{"XDG_CACHE_HOME": "/xdg", "HOME": "/home/me"}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	if dir, err := DefaultDir(lookup); err != nil || dir != filepath.Join("/xdg", "whisper-cli") {
+		t.Fatalf("DefaultDir = %s, %v", dir, err)
+	}
+
+	delete(env, "XDG_CACHE_HOME")
+	if dir, err := DefaultDir(lookup); err != nil || dir != filepath.Join("/home/me", ".cache", "whisper-cli") {
+		t.Fatalf("DefaultDir without XDG_CACHE_HOME = %s, %v", dir, err)
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/arykalin/whisper-cli/internal/app"
+)
+
+// Exit codes of whisper-cli. They are part of the interface: wrappers such
+// as cron jobs branch on them, so existing values must not change.
+const (
+	ExitOK = 0
+	// ExitFailure covers failures without a more specific code.
+	ExitFailure = 1
+	// ExitConfig is an invalid flag, environment variable or setting.
+	ExitConfig = 2
+	// ExitPartial means some files failed or have gaps while the others
+	// were transcribed; report.json lists which.
+	ExitPartial       = 3
+	ExitMissingFFmpeg = 4
+	ExitAuth          = 5
+	// ExitQuota is an exhausted quota or a rate limit that outlasted the
+	// retries.
+	ExitQuota = 6
+)
+
+// ExitCode maps the error returned by Run to the process exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	switch app.ClassifyError(err) {
+	case app.ErrorClassConfig:
+		return ExitConfig
+	case app.ErrorClassPartial:
+		return ExitPartial
+	case app.ErrorClassMissingFFmpeg:
+		return ExitMissingFFmpeg
+	case app.ErrorClassAuth:
+		return ExitAuth
+	case app.ErrorClassQuota:
+		return ExitQuota
+	default:
+		return ExitFailure
+	}
+}
+
+func configError(err error) error {
+	var classified *app.ClassError
+	if errors.As(err, &classified) {
+		return err
+	}
+	return &app.ClassError{Class: app.ErrorClassConfig, Err: err}
+}
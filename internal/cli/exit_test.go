@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/arykalin/whisper-cli/internal/app"
+)
+
+func TestExitCodeForUsageAndConfigErrors(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), testApplication(), []string{"--unknown"}, &stdout, &stderr)
+	if code := ExitCode(err); code != ExitConfig {
+		t.Fatalf("unknown flag exit code = %d, want %d", code, ExitConfig)
+	}
+
+	err = Run(context.Background(), testApplication(), []string{"--provider", "openai"}, &stdout, &stderr)
+	if code := ExitCode(err); code != ExitConfig {
+		t.Fatalf("missing input exit code = %d, want %d", code, ExitConfig)
+	}
+}
+
+func TestExitCodeFollowsErrorClass(t *testing.T) {
+	t.Parallel()
+
+	if code := ExitCode(nil); code != ExitOK {
+		t.Fatalf("nil exit code = %d", code)
+	}
+	if code := ExitCode(errors.New("boom")); code != ExitFailure {
+		t.Fatalf("plain error exit code = %d", code)
+	}
+	wrapped := fmt.Errorf("run: %w", &app.ClassError{Class: app.ErrorClassMissingFFmpeg, Err: errors.New("ffmpeg not found in PATH")})
+	if code := ExitCode(wrapped); code != ExitMissingFFmpeg {
+		t.Fatalf("missing ffmpeg exit code = %d", code)
+	}
+	if code := ExitCode(&app.ClassError{Class: app.ErrorClassQuota, Err: errors.New("quota")}); code != ExitQuota {
+		t.Fatalf("quota exit code = %d", code)
+	}
+	if code := ExitCode(&app.ClassError{Class: app.ErrorClassPartial, Err: errors.New("1 of 2 files failed")}); code != ExitPartial {
+		t.Fatalf("partial exit code = %d", code)
+	}
+}
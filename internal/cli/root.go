@@ -2,13 +2,22 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"os/signal"
 	"runtime"
 	"slices"
+	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/arykalin/whisper-cli/internal/app"
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/cache"
 	"github.com/arykalin/whisper-cli/internal/config"
 	"github.com/arykalin/whisper-cli/internal/domain"
 	"github.com/arykalin/whisper-cli/internal/provider"
@@ -21,12 +30,19 @@ type rootOptions struct {
 
 func newRootOptions() rootOptions {
 	var opts rootOptions
+	opts.overrides.Task.Value = string(domain.TaskTranscribe)
 	opts.overrides.Provider.Value = string(config.DefaultProvider)
 	opts.overrides.OutputDir.Value = "output"
 	opts.overrides.Language.Value = "ru"
 	opts.overrides.Outputs.Value = "timestamps"
 	opts.overrides.ChunkSeconds.Value = 600
+	opts.overrides.ChunkMode.Value = string(audio.ChunkModeFixed)
+	opts.overrides.SilenceTolerance.Value = 30
+	opts.overrides.ChunkOverlap.Value = 0
+	opts.overrides.RemoveSilence.Value = 0
+	opts.overrides.Speed.Value = "1"
 	opts.overrides.Concurrency.Value = runtime.NumCPU()
+	opts.overrides.FFmpegJobs.Value = config.DefaultFFmpegJobs
 	return opts
 }
 
@@ -36,9 +52,16 @@ func Run(ctx context.Context, application *app.Application, args []string, stdou
 	cmd.SetOut(stdout)
 	cmd.SetErr(stderr)
 
+	// Errors before any command runs come from flag or argument parsing.
+	parsed := false
+	cmd.PersistentPreRun = func(*cobra.Command, []string) { parsed = true }
+
 	err := cmd.ExecuteContext(ctx)
 	if err != nil {
 		_, _ = fmt.Fprintln(stderr, err)
+		if !parsed {
+			err = configError(err)
+		}
 	}
 	return err
 }
@@ -55,32 +78,250 @@ func NewRootCommand(application *app.Application) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg, err := config.Resolve(opts.overrides, envSource(application))
 			if err != nil {
-				return err
+				return configError(err)
 			}
 			return application.Run(cmd.Context(), cfg)
 		},
 	}
 
-	flags := root.Flags()
+	bindRunFlags(root, application, &opts)
+
+	root.AddCommand(newCompletionCommand(root))
+	root.AddCommand(newAudioTracksCommand(application))
+	root.AddCommand(newCacheCommand(application))
+	root.AddCommand(newWatchCommand(application))
+	return root
+}
+
+// bindRunFlags registers the transcription flags shared by the root and
+// watch commands.
+func bindRunFlags(cmd *cobra.Command, application *app.Application, opts *rootOptions) {
+	flags := cmd.Flags()
 	flags.SortFlags = false
-	flags.Var(&opts.overrides.Provider, "provider", "Provider: openai, groq, openrouter")
+	flags.Var(&opts.overrides.Task, "task", "Task: transcribe, or translate to English")
+	flags.Var(&opts.overrides.Provider, "provider", "Provider: openai, groq, openrouter or a declared OpenAI-compatible provider")
 	flags.Var(&opts.overrides.Model, "model", "Model name")
-	flags.Var(&opts.overrides.Input, "input", "Input media file or directory")
+	flags.Var(&opts.overrides.Input, "input", "Input media file or directory; - reads media from stdin")
+	flags.Var(&opts.overrides.InputList, "input-list", "File with newline- or NUL-separated input paths and globs; - reads the list from stdin")
 	flags.Var(&opts.overrides.OutputDir, "output-dir", "Output directory root")
 	flags.Var(&opts.overrides.Language, "language", "Language code")
-	flags.Var(&opts.overrides.Outputs, "outputs", "Optional artifacts: timestamps,srt,vtt,diarized,raw or none")
+	flags.Var(&opts.overrides.Outputs, "outputs", "Optional artifacts: timestamps,srt,vtt,diarized,words,raw or none")
 	flags.Var(&opts.overrides.ChunkSeconds, "chunk-seconds", "Chunk size in seconds")
-	flags.Var(&opts.overrides.Concurrency, "concurrency", "Number of worker goroutines")
+	flags.Var(&opts.overrides.ChunkMode, "chunk-mode", "Chunk boundaries: fixed, or silence to cut at the nearest pause")
+	flags.Var(&opts.overrides.SilenceTolerance, "silence-tolerance", "Seconds a silence-aware cut may move away from --chunk-seconds")
+	flags.Var(&opts.overrides.ChunkOverlap, "chunk-overlap", "Seconds of audio shared by neighbouring chunks; duplicates are removed when stitching")
+	flags.Var(&opts.overrides.AudioProfile, "audio-profile", "Preprocessing profile: default, speech-aac, speech-flac or speech-opus; empty uses the provider default")
+	flags.Var(&opts.overrides.RemoveSilence, "remove-silence", "Strip silences of at least this many seconds before chunking; 0 keeps them")
+	flags.Var(&opts.overrides.Speed, "speed", "Speed audio up by this factor (1.25-2) before upload; 1 keeps the original tempo")
+	flags.Var(&opts.overrides.AudioFilter, "audio-filter", "Filter presets applied before upload: loudnorm,afftdn,highpass,lowpass")
+	flags.Var(&opts.overrides.AudioFilterRaw, "audio-filter-raw", "Raw ffmpeg -af filter chain applied after --audio-filter presets")
+	flags.Var(&opts.overrides.AudioTrack, "audio-track", "Audio stream to transcribe: track number (0:a:N) or language tag such as eng")
+	flags.Var(&opts.overrides.ChannelLabels, "channel-labels", "Transcribe each channel separately and label its speaker, e.g. agent,customer")
+	flags.Var(&opts.overrides.Start, "start", "Transcribe from this position: seconds, MM:SS or HH:MM:SS")
+	flags.Var(&opts.overrides.End, "end", "Transcribe up to this position: seconds, MM:SS or HH:MM:SS")
+	flags.Var(&opts.overrides.Ranges, "ranges", "Transcribe only these windows, e.g. 10:00-20:00,1:05:00-")
+	flags.Var(&opts.overrides.Recursive, "recursive", "Scan directory input recursively and mirror its tree under --output-dir")
+	flags.Var(&opts.overrides.Include, "include", "Comma-separated globs a file must match in directory input, e.g. *.mp3,talks/*")
+	flags.Var(&opts.overrides.Exclude, "exclude", "Comma-separated globs for files and directories to skip in directory input")
+	flags.Var(&opts.overrides.MinSize, "min-size", "Skip files smaller than this size, e.g. 500K or 10M")
+	flags.Var(&opts.overrides.ModifiedSince, "modified-since", "Skip files modified before YYYY-MM-DD, an RFC 3339 time or a duration ago such as 72h")
+	flags.Var(&opts.overrides.Concurrency, "concurrency", "Maximum in-flight provider requests across all files")
+	flags.Var(&opts.overrides.FFmpegJobs, "ffmpeg-jobs", "Maximum concurrent ffmpeg preprocessing and chunking jobs")
+	flags.Var(&opts.overrides.Fresh, "fresh", "Ignore chunk results checkpointed in _work or cached by earlier runs")
+	flags.Var(&opts.overrides.Cache, "cache", "Reuse and store chunk results in the shared transcription cache")
+	flags.Var(&opts.overrides.CacheDir, "cache-dir", "Transcription cache directory; defaults to $XDG_CACHE_HOME/whisper-cli")
+	flags.Var(&opts.overrides.Incremental, "incremental", "Skip inputs whose content, settings and outputs match manifest.json of --output-dir")
+	flags.Var(&opts.overrides.AllowPartial, "allow-partial", "Write transcripts with gap markers when some chunks fail instead of failing the file")
 	flags.Var(&opts.overrides.Prompt, "prompt", "Prompt for supported models")
 
-	must(root.RegisterFlagCompletionFunc("provider", completeProviders(application.Registry)))
-	must(root.RegisterFlagCompletionFunc("model", completeModels(application.Registry, &opts)))
-	must(root.RegisterFlagCompletionFunc("outputs", completeOutputs))
-	must(root.RegisterFlagCompletionFunc("input", completeInputPaths))
-	must(root.MarkFlagDirname("output-dir"))
+	must(cmd.RegisterFlagCompletionFunc("task", completeTasks))
+	must(cmd.RegisterFlagCompletionFunc("provider", completeProviders(application.Registry)))
+	must(cmd.RegisterFlagCompletionFunc("model", completeModels(application.Registry, opts)))
+	must(cmd.RegisterFlagCompletionFunc("outputs", completeOutputs))
+	must(cmd.RegisterFlagCompletionFunc("chunk-mode", completeChunkModes))
+	must(cmd.RegisterFlagCompletionFunc("audio-profile", completeAudioProfiles))
+	must(cmd.RegisterFlagCompletionFunc("audio-filter", completeAudioFilters))
+	must(cmd.RegisterFlagCompletionFunc("input", completeInputPaths))
+	must(cmd.MarkFlagFilename("input-list"))
+	must(cmd.MarkFlagDirname("output-dir"))
+	flags.Lookup("recursive").NoOptDefVal = "true"
+	flags.Lookup("fresh").NoOptDefVal = "true"
+	flags.Lookup("cache").NoOptDefVal = "true"
+	flags.Lookup("incremental").NoOptDefVal = "true"
+	flags.Lookup("allow-partial").NoOptDefVal = "true"
+	must(cmd.MarkFlagDirname("cache-dir"))
+}
 
-	root.AddCommand(newCompletionCommand(root))
-	return root
+func newWatchCommand(application *app.Application) *cobra.Command {
+	opts := newRootOptions()
+	var watchOverrides config.WatchOverrides
+	watchOverrides.Debounce.Value = config.DefaultWatchDebounce.String()
+	watchOverrides.FileJobs.Value = config.DefaultWatchFileJobs
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Transcribe media files as they are written into the --input directory",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Resolve(opts.overrides, envSource(application))
+			if err != nil {
+				return configError(err)
+			}
+			watchOpts, err := config.ResolveWatch(watchOverrides, envSource(application))
+			if err != nil {
+				return configError(err)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return application.Watch(ctx, cfg, watchOpts)
+		},
+	}
+
+	bindRunFlags(cmd, application, &opts)
+	flags := cmd.Flags()
+	flags.Var(&watchOverrides.Debounce, "debounce", "How long a new file must stay unchanged before it is transcribed")
+	flags.Var(&watchOverrides.FileJobs, "file-jobs", "Maximum files transcribed at once")
+	flags.Var(&watchOverrides.DoneDir, "done-dir", "Move transcribed files into this directory")
+	flags.Var(&watchOverrides.FailedDir, "failed-dir", "Move files that failed to transcribe into this directory")
+	must(cmd.MarkFlagDirname("done-dir"))
+	must(cmd.MarkFlagDirname("failed-dir"))
+	return cmd
+}
+
+func newAudioTracksCommand(application *app.Application) *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "audio-tracks FILE",
+		Short: "List the audio streams of a media file for --audio-track",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			streams, err := application.AudioStreams(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(streams)
+			}
+
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(writer, "TRACK\tINDEX\tLANGUAGE\tCODEC\tCHANNELS\tSAMPLE RATE\tTITLE")
+			for _, stream := range streams {
+				_, _ = fmt.Fprintf(writer, "%d\t%d\t%s\t%s\t%d\t%d\t%s\n",
+					stream.Track, stream.Index, stream.Language, stream.Codec, stream.Channels, stream.SampleRate, stream.Title)
+			}
+			return writer.Flush()
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print streams as JSON")
+	return cmd
+}
+
+func newCacheCommand(application *app.Application) *cobra.Command {
+	var dirOverride config.StringOverride
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and clean the shared transcription cache",
+		Args:  cobra.NoArgs,
+	}
+	cmd.PersistentFlags().Var(&dirOverride, "cache-dir", "Transcription cache directory; defaults to $XDG_CACHE_HOME/whisper-cli")
+	must(cmd.MarkPersistentFlagDirname("cache-dir"))
+
+	store := func() (cache.Store, error) {
+		dir, err := config.ResolveCacheDir(dirOverride, envSource(application))
+		if err != nil {
+			return cache.Store{}, configError(err)
+		}
+		return application.CacheStore(dir), nil
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "stats",
+		Short: "Print the number and size of cached chunk results",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := store()
+			if err != nil {
+				return err
+			}
+			stats, err := store.Stats()
+			if err != nil {
+				return err
+			}
+
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintf(writer, "directory\t%s\n", store.Dir)
+			_, _ = fmt.Fprintf(writer, "entries\t%d\n", stats.Entries)
+			_, _ = fmt.Fprintf(writer, "size\t%d bytes\n", stats.Bytes)
+			if stats.Entries > 0 {
+				_, _ = fmt.Fprintf(writer, "oldest\t%s\n", stats.Oldest.Format(time.RFC3339))
+				_, _ = fmt.Fprintf(writer, "newest\t%s\n", stats.Newest.Format(time.RFC3339))
+			}
+			return writer.Flush()
+		},
+	})
+
+	var olderThan string
+	prune := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove cached chunk results older than --older-than",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			age, err := parseAge(olderThan)
+			if err != nil {
+				return configError(err)
+			}
+			store, err := store()
+			if err != nil {
+				return err
+			}
+			removed, err := store.Prune(time.Now().Add(-age))
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "removed %d cache entries\n", removed)
+			return err
+		},
+	}
+	prune.Flags().StringVar(&olderThan, "older-than", "", "Age of entries to remove, e.g. 720h or 30d")
+	must(prune.MarkFlagRequired("older-than"))
+	cmd.AddCommand(prune)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Remove every cached chunk result",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := store()
+			if err != nil {
+				return err
+			}
+			removed, err := store.Clear()
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "removed %d cache entries\n", removed)
+			return err
+		},
+	})
+	return cmd
+}
+
+// parseAge reads a Go duration, or a whole number of days such as 30d.
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err == nil && count > 0 {
+			return time.Duration(count) * 24 * time.Hour, nil
+		}
+	} else if age, err := time.ParseDuration(value); err == nil && age > 0 {
+		return age, nil
+	}
+	return 0, fmt.Errorf("invalid --older-than %q; use a duration such as 720h or a day count such as 30d", value)
 }
 
 func newCompletionCommand(root *cobra.Command) *cobra.Command {
@@ -132,6 +373,36 @@ func completeModels(registry provider.Registry, opts *rootOptions) func(*cobra.C
 	}
 }
 
+func completeTasks(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var matches []string
+	for _, task := range []domain.Task{domain.TaskTranscribe, domain.TaskTranslate} {
+		if strings.HasPrefix(string(task), toComplete) {
+			matches = append(matches, string(task))
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeChunkModes(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var matches []string
+	for _, mode := range []audio.ChunkMode{audio.ChunkModeFixed, audio.ChunkModeSilence} {
+		if strings.HasPrefix(string(mode), toComplete) {
+			matches = append(matches, string(mode))
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeAudioProfiles(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var matches []string
+	for _, name := range audio.ProfileNames() {
+		if strings.HasPrefix(string(name), toComplete) {
+			matches = append(matches, string(name))
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
 func completeOutputs(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	base := ""
 	fragment := toComplete
@@ -168,6 +439,24 @@ func completeOutputs(_ *cobra.Command, _ []string, toComplete string) ([]string,
 	return matches, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 }
 
+func completeAudioFilters(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	base, fragment := "", toComplete
+	if idx := strings.LastIndex(toComplete, ","); idx >= 0 {
+		base, fragment = toComplete[:idx+1], toComplete[idx+1:]
+	}
+
+	used := strings.Split(base, ",")
+	var matches []string
+	for _, preset := range audio.FilterPresetNames() {
+		name := string(preset)
+		if slices.Contains(used, name) || !strings.HasPrefix(name, fragment) {
+			continue
+		}
+		matches = append(matches, base+name)
+	}
+	return matches, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
 func completeInputPaths(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 	return nil, cobra.ShellCompDirectiveDefault
 }
@@ -7,7 +7,9 @@ import (
 	"testing"
 
 	"github.com/arykalin/whisper-cli/internal/app"
+	"github.com/arykalin/whisper-cli/internal/cache"
 	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
 	"github.com/arykalin/whisper-cli/internal/provider"
 )
 
@@ -151,3 +153,46 @@ func TestRunRejectsLegacySingleDashLongFlag(t *testing.T) {
 		t.Fatalf("unexpected stderr: %q", stderr.String())
 	}
 }
+
+type mapEnv map[string]string
+
+func (m mapEnv) LookupEnv(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func TestRunCacheStatsAndClear(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	application := testApplication()
+	application.FS = fsx.OS{}
+	application.Env = mapEnv{"WHISPER_CLI_CACHE_DIR": dir}
+	if err := application.CacheStore(dir).Put("ab12", cache.Entry{Transcript: domain.Transcript{Text: "hi"}}); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := Run(context.Background(), application, []string{"cache", "stats"}, &stdout, &stderr); err != nil {
+		t.Fatalf("cache stats returned error: %v; stderr: %s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "entries    1") {
+		t.Fatalf("unexpected stats output: %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := Run(context.Background(), application, []string{"cache", "prune", "--older-than", "30d"}, &stdout, &stderr); err != nil {
+		t.Fatalf("cache prune returned error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "removed 0 cache entries") {
+		t.Fatalf("unexpected prune output: %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := Run(context.Background(), application, []string{"cache", "clear"}, &stdout, &stderr); err != nil {
+		t.Fatalf("cache clear returned error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "removed 1 cache entries") {
+		t.Fatalf("unexpected clear output: %q", stdout.String())
+	}
+}
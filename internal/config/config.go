@@ -4,15 +4,31 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/arykalin/whisper-cli/internal/audio"
+	"github.com/arykalin/whisper-cli/internal/cache"
 	"github.com/arykalin/whisper-cli/internal/domain"
 )
 
 const DefaultProvider = domain.ProviderOpenAI
 
+// DefaultWatchDebounce is how long a watched file must stay unchanged before
+// it is transcribed.
+const DefaultWatchDebounce = 2 * time.Second
+
+// DefaultWatchFileJobs bounds the files watch mode transcribes at once.
+const DefaultWatchFileJobs = 2
+
+// DefaultFFmpegJobs bounds concurrent ffmpeg passes; ffmpeg already uses
+// several threads per job, so a small number keeps the machine responsive.
+const DefaultFFmpegJobs = 2
+
 type StringOverride struct {
 	Value    string
 	Provided bool
@@ -63,28 +79,122 @@ func (i *IntOverride) Type() string {
 	return "int"
 }
 
+type BoolOverride struct {
+	Value    bool
+	Provided bool
+}
+
+func (b *BoolOverride) String() string {
+	return strconv.FormatBool(b.Value)
+}
+
+func (b *BoolOverride) SetValue(value bool) {
+	b.Value = value
+	b.Provided = true
+}
+
+func (b *BoolOverride) Set(value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	b.SetValue(parsed)
+	return nil
+}
+
+func (b *BoolOverride) Type() string {
+	return "bool"
+}
+
+// IsBoolFlag lets the flag be passed without a value.
+func (b *BoolOverride) IsBoolFlag() bool {
+	return true
+}
+
 type Overrides struct {
-	Provider     StringOverride
-	Model        StringOverride
-	Input        StringOverride
-	OutputDir    StringOverride
-	Language     StringOverride
-	Outputs      StringOverride
-	ChunkSeconds IntOverride
-	Concurrency  IntOverride
-	Prompt       StringOverride
+	Task             StringOverride
+	Provider         StringOverride
+	Model            StringOverride
+	Input            StringOverride
+	InputList        StringOverride
+	OutputDir        StringOverride
+	Language         StringOverride
+	Outputs          StringOverride
+	ChunkSeconds     IntOverride
+	ChunkMode        StringOverride
+	SilenceTolerance IntOverride
+	ChunkOverlap     IntOverride
+	AudioProfile     StringOverride
+	RemoveSilence    IntOverride
+	Speed            StringOverride
+	AudioFilter      StringOverride
+	AudioFilterRaw   StringOverride
+	AudioTrack       StringOverride
+	ChannelLabels    StringOverride
+	Start            StringOverride
+	End              StringOverride
+	Ranges           StringOverride
+	Recursive        BoolOverride
+	Include          StringOverride
+	Exclude          StringOverride
+	MinSize          StringOverride
+	ModifiedSince    StringOverride
+	Concurrency      IntOverride
+	FFmpegJobs       IntOverride
+	Fresh            BoolOverride
+	Cache            BoolOverride
+	CacheDir         StringOverride
+	Incremental      BoolOverride
+	AllowPartial     BoolOverride
+	Prompt           StringOverride
 }
 
 type Config struct {
-	Provider     domain.Provider
-	Model        string
-	Input        string
-	OutputDir    string
-	Language     string
-	Outputs      domain.ArtifactSet
-	ChunkSeconds int
-	Concurrency  int
-	Prompt       string
+	Task             domain.Task
+	Provider         domain.Provider
+	Model            string
+	Input            string
+	InputList        string
+	OutputDir        string
+	Language         string
+	Outputs          domain.ArtifactSet
+	ChunkSeconds     int
+	ChunkMode        audio.ChunkMode
+	SilenceTolerance int
+	ChunkOverlap     int
+	AudioProfile     audio.ProfileName
+	RemoveSilence    int
+	Speed            float64
+	AudioFilters     []audio.FilterPreset
+	AudioFilterRaw   string
+	AudioTrack       string
+	ChannelLabels    []string
+	Ranges           []audio.TimeRange
+	Scan             audio.ScanOptions
+	Concurrency      int
+	FFmpegJobs       int
+	Fresh            bool
+	Cache            bool
+	CacheDir         string
+	Incremental      bool
+	AllowPartial     bool
+	Prompt           string
+}
+
+// WatchOverrides are the flags of the watch command on top of Overrides.
+type WatchOverrides struct {
+	Debounce  StringOverride
+	FileJobs  IntOverride
+	DoneDir   StringOverride
+	FailedDir StringOverride
+}
+
+// WatchOptions controls how watch mode picks up and files away inputs.
+type WatchOptions struct {
+	Debounce  time.Duration
+	FileJobs  int
+	DoneDir   string
+	FailedDir string
 }
 
 type EnvSource interface {
@@ -104,6 +214,8 @@ func Resolve(overrides Overrides, env EnvSource) (Config, error) {
 	}
 
 	input := chooseString(overrides.Input, env, "WHISPER_CLI_INPUT", "")
+	inputList := chooseString(overrides.InputList, env, "WHISPER_CLI_INPUT_LIST", "")
+	taskRaw := chooseString(overrides.Task, env, "WHISPER_CLI_TASK", string(domain.TaskTranscribe))
 	providerName := chooseString(overrides.Provider, env, "WHISPER_CLI_PROVIDER", string(DefaultProvider))
 	model := chooseString(overrides.Model, env, "WHISPER_CLI_MODEL", "")
 	if model == "" {
@@ -114,44 +226,319 @@ func Resolve(overrides Overrides, env EnvSource) (Config, error) {
 	language := chooseString(overrides.Language, env, "WHISPER_CLI_LANGUAGE", "ru")
 	outputsRaw := chooseString(overrides.Outputs, env, "WHISPER_CLI_OUTPUTS", "timestamps")
 	chunkSeconds := chooseInt(overrides.ChunkSeconds, env, "WHISPER_CLI_CHUNK_SECONDS", 600)
+	chunkModeRaw := chooseString(overrides.ChunkMode, env, "WHISPER_CLI_CHUNK_MODE", string(audio.ChunkModeFixed))
+	silenceTolerance := chooseInt(overrides.SilenceTolerance, env, "WHISPER_CLI_SILENCE_TOLERANCE", 30)
+	chunkOverlap := chooseInt(overrides.ChunkOverlap, env, "WHISPER_CLI_CHUNK_OVERLAP", 0)
+	audioProfileRaw := chooseString(overrides.AudioProfile, env, "WHISPER_CLI_AUDIO_PROFILE", "")
+	removeSilence := chooseInt(overrides.RemoveSilence, env, "WHISPER_CLI_REMOVE_SILENCE", 0)
+	speedRaw := chooseString(overrides.Speed, env, "WHISPER_CLI_SPEED", "1")
+	audioFilterRaw := chooseString(overrides.AudioFilter, env, "WHISPER_CLI_AUDIO_FILTER", "")
+	audioFilterExpr := chooseString(overrides.AudioFilterRaw, env, "WHISPER_CLI_AUDIO_FILTER_RAW", "")
+	audioTrack := chooseString(overrides.AudioTrack, env, "WHISPER_CLI_AUDIO_TRACK", "")
+	channelLabelsRaw := chooseString(overrides.ChannelLabels, env, "WHISPER_CLI_CHANNEL_LABELS", "")
+	startRaw := chooseString(overrides.Start, env, "WHISPER_CLI_START", "")
+	endRaw := chooseString(overrides.End, env, "WHISPER_CLI_END", "")
+	rangesRaw := chooseString(overrides.Ranges, env, "WHISPER_CLI_RANGES", "")
+	recursive := chooseBool(overrides.Recursive, env, "WHISPER_CLI_RECURSIVE", false)
+	includeRaw := chooseString(overrides.Include, env, "WHISPER_CLI_INCLUDE", "")
+	excludeRaw := chooseString(overrides.Exclude, env, "WHISPER_CLI_EXCLUDE", "")
+	minSizeRaw := chooseString(overrides.MinSize, env, "WHISPER_CLI_MIN_SIZE", "")
+	modifiedSinceRaw := chooseString(overrides.ModifiedSince, env, "WHISPER_CLI_MODIFIED_SINCE", "")
 	concurrency := chooseInt(overrides.Concurrency, env, "WHISPER_CLI_CONCURRENCY", runtime.NumCPU())
+	ffmpegJobs := chooseInt(overrides.FFmpegJobs, env, "WHISPER_CLI_FFMPEG_JOBS", DefaultFFmpegJobs)
+	fresh := chooseBool(overrides.Fresh, env, "WHISPER_CLI_FRESH", false)
+	useCache := chooseBool(overrides.Cache, env, "WHISPER_CLI_CACHE", false)
+	incremental := chooseBool(overrides.Incremental, env, "WHISPER_CLI_INCREMENTAL", false)
+	allowPartial := chooseBool(overrides.AllowPartial, env, "WHISPER_CLI_ALLOW_PARTIAL", false)
 	prompt := chooseString(overrides.Prompt, env, "WHISPER_CLI_PROMPT", "")
 
-	if input == "" {
-		return Config{}, errors.New("no input specified; use --input or WHISPER_CLI_INPUT")
+	input = strings.TrimSpace(input)
+	inputList = strings.TrimSpace(inputList)
+	if input == "" && inputList == "" {
+		return Config{}, errors.New("no input specified; use --input or WHISPER_CLI_INPUT, or --input-list or WHISPER_CLI_INPUT_LIST")
+	}
+	if input != "" && inputList != "" {
+		return Config{}, errors.New("input and input-list cannot be combined")
 	}
 	if concurrency <= 0 {
 		return Config{}, errors.New("concurrency must be greater than zero")
 	}
+	if ffmpegJobs <= 0 {
+		return Config{}, errors.New("ffmpeg-jobs must be greater than zero")
+	}
 	if chunkSeconds <= 0 {
 		return Config{}, errors.New("chunk-seconds must be greater than zero")
 	}
+	if silenceTolerance < 0 {
+		return Config{}, errors.New("silence-tolerance must not be negative")
+	}
+	if removeSilence < 0 {
+		return Config{}, errors.New("remove-silence must not be negative")
+	}
 
 	providerValue := domain.Provider(strings.ToLower(strings.TrimSpace(providerName)))
-	switch providerValue {
-	case domain.ProviderOpenAI, domain.ProviderGroq, domain.ProviderOpenRouter:
-	default:
+	if !domain.ValidProviderName(string(providerValue)) {
 		return Config{}, fmt.Errorf("unsupported provider %q", providerName)
 	}
 
+	task, err := domain.ParseTask(taskRaw)
+	if err != nil {
+		return Config{}, err
+	}
+
+	chunkMode, err := audio.ParseChunkMode(chunkModeRaw)
+	if err != nil {
+		return Config{}, err
+	}
+	if chunkOverlap < 0 || chunkOverlap >= chunkSeconds {
+		return Config{}, errors.New("chunk-overlap must be between 0 and chunk-seconds")
+	}
+	if chunkOverlap > 0 && chunkMode == audio.ChunkModeSilence {
+		return Config{}, errors.New("chunk-overlap cannot be combined with chunk-mode silence")
+	}
+
+	speed, err := strconv.ParseFloat(speedRaw, 64)
+	if err != nil || (speed != 1 && (speed < 1.25 || speed > 2)) {
+		return Config{}, fmt.Errorf("speed must be 1 or between 1.25 and 2, got %q", speedRaw)
+	}
+
+	audioFilters, err := audio.ParseFilterPresets(audioFilterRaw)
+	if err != nil {
+		return Config{}, err
+	}
+
+	channelLabels, err := parseChannelLabels(channelLabelsRaw)
+	if err != nil {
+		return Config{}, err
+	}
+
+	ranges, err := parseRanges(startRaw, endRaw, rangesRaw)
+	if err != nil {
+		return Config{}, err
+	}
+	if len(ranges) > 0 && removeSilence > 0 {
+		return Config{}, errors.New("remove-silence cannot be combined with start, end or ranges")
+	}
+
+	scan, err := parseScanOptions(recursive, includeRaw, excludeRaw, minSizeRaw, modifiedSinceRaw, time.Now())
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cacheDir string
+	if useCache {
+		if cacheDir, err = ResolveCacheDir(overrides.CacheDir, env); err != nil {
+			return Config{}, err
+		}
+	}
+
+	audioProfile, err := audio.ParseProfileName(audioProfileRaw)
+	if err != nil {
+		return Config{}, err
+	}
+
 	outputs, err := domain.ParseArtifactSet(outputsRaw)
 	if err != nil {
 		return Config{}, err
 	}
 
 	return Config{
-		Provider:     providerValue,
-		Model:        strings.TrimSpace(model),
-		Input:        strings.TrimSpace(input),
-		OutputDir:    strings.TrimSpace(outputDir),
-		Language:     strings.TrimSpace(language),
-		Outputs:      outputs,
-		ChunkSeconds: chunkSeconds,
-		Concurrency:  concurrency,
-		Prompt:       strings.TrimSpace(prompt),
+		Task:             task,
+		Provider:         providerValue,
+		Model:            strings.TrimSpace(model),
+		Input:            input,
+		InputList:        inputList,
+		OutputDir:        strings.TrimSpace(outputDir),
+		Language:         strings.TrimSpace(language),
+		Outputs:          outputs,
+		ChunkSeconds:     chunkSeconds,
+		ChunkMode:        chunkMode,
+		SilenceTolerance: silenceTolerance,
+		ChunkOverlap:     chunkOverlap,
+		AudioProfile:     audioProfile,
+		RemoveSilence:    removeSilence,
+		Speed:            speed,
+		AudioFilters:     audioFilters,
+		AudioFilterRaw:   audioFilterExpr,
+		AudioTrack:       audioTrack,
+		ChannelLabels:    channelLabels,
+		Ranges:           ranges,
+		Scan:             scan,
+		Concurrency:      concurrency,
+		FFmpegJobs:       ffmpegJobs,
+		Fresh:            fresh,
+		Cache:            useCache,
+		CacheDir:         cacheDir,
+		Incremental:      incremental,
+		AllowPartial:     allowPartial,
+		Prompt:           strings.TrimSpace(prompt),
+	}, nil
+}
+
+// ResolveWatch applies the same flags > env > defaults precedence to the
+// watch command options.
+func ResolveWatch(overrides WatchOverrides, env EnvSource) (WatchOptions, error) {
+	if env == nil {
+		env = OSEnv{}
+	}
+
+	debounceRaw := chooseString(overrides.Debounce, env, "WHISPER_CLI_WATCH_DEBOUNCE", DefaultWatchDebounce.String())
+	fileJobs := chooseInt(overrides.FileJobs, env, "WHISPER_CLI_WATCH_FILE_JOBS", DefaultWatchFileJobs)
+
+	debounce, err := time.ParseDuration(debounceRaw)
+	if err != nil || debounce <= 0 {
+		return WatchOptions{}, fmt.Errorf("debounce must be a positive duration such as 2s, got %q", debounceRaw)
+	}
+	if fileJobs <= 0 {
+		return WatchOptions{}, errors.New("file-jobs must be greater than zero")
+	}
+
+	return WatchOptions{
+		Debounce:  debounce,
+		FileJobs:  fileJobs,
+		DoneDir:   chooseString(overrides.DoneDir, env, "WHISPER_CLI_WATCH_DONE_DIR", ""),
+		FailedDir: chooseString(overrides.FailedDir, env, "WHISPER_CLI_WATCH_FAILED_DIR", ""),
 	}, nil
 }
 
+// ResolveCacheDir returns the transcription cache directory: the override,
+// then WHISPER_CLI_CACHE_DIR, then the XDG cache directory.
+func ResolveCacheDir(override StringOverride, env EnvSource) (string, error) {
+	if env == nil {
+		env = OSEnv{}
+	}
+	if dir := chooseString(override, env, "WHISPER_CLI_CACHE_DIR", ""); dir != "" {
+		return dir, nil
+	}
+	return cache.DefaultDir(env.LookupEnv)
+}
+
+func parseScanOptions(recursive bool, includeRaw string, excludeRaw string, minSizeRaw string, modifiedSinceRaw string, now time.Time) (audio.ScanOptions, error) {
+	scan := audio.ScanOptions{Recursive: recursive}
+
+	var err error
+	if scan.Include, err = parseGlobs("include", includeRaw); err != nil {
+		return audio.ScanOptions{}, err
+	}
+	if scan.Exclude, err = parseGlobs("exclude", excludeRaw); err != nil {
+		return audio.ScanOptions{}, err
+	}
+	if minSizeRaw != "" {
+		if scan.MinSize, err = parseSize(minSizeRaw); err != nil {
+			return audio.ScanOptions{}, err
+		}
+	}
+	if modifiedSinceRaw != "" {
+		if scan.ModifiedSince, err = parseSince(modifiedSinceRaw, now); err != nil {
+			return audio.ScanOptions{}, err
+		}
+	}
+	return scan, nil
+}
+
+func parseGlobs(name string, value string) ([]string, error) {
+	var patterns []string
+	for _, part := range strings.Split(value, ",") {
+		pattern := strings.TrimSpace(part)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s pattern %q: %w", name, pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// parseSize reads a byte count with an optional K, M or G suffix (powers of 1024).
+func parseSize(value string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for suffix, factor := range map[string]int64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30} {
+		if trimmed, ok := strings.CutSuffix(number, suffix); ok {
+			number, multiplier = trimmed, factor
+			break
+		}
+	}
+	parsed, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid min-size %q; use bytes or a K, M, G suffix", value)
+	}
+	return parsed * multiplier, nil
+}
+
+// parseSince reads a date, an RFC 3339 timestamp or a duration back from now.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if since, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return since, nil
+	}
+	if since, err := time.Parse(time.RFC3339, value); err == nil {
+		return since, nil
+	}
+	if age, err := time.ParseDuration(value); err == nil && age >= 0 {
+		return now.Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid modified-since %q; use YYYY-MM-DD, RFC 3339 or a duration such as 72h", value)
+}
+
+// parseRanges turns --start/--end or --ranges into time ranges; they are
+// mutually exclusive.
+func parseRanges(startRaw string, endRaw string, rangesRaw string) ([]audio.TimeRange, error) {
+	if rangesRaw != "" {
+		if startRaw != "" || endRaw != "" {
+			return nil, errors.New("ranges cannot be combined with start or end")
+		}
+		return audio.ParseTimeRanges(rangesRaw)
+	}
+	if startRaw == "" && endRaw == "" {
+		return nil, nil
+	}
+
+	var (
+		window audio.TimeRange
+		err    error
+	)
+	if startRaw != "" {
+		if window.Start, err = audio.ParseTimestamp(startRaw); err != nil {
+			return nil, fmt.Errorf("start: %w", err)
+		}
+	}
+	if endRaw != "" {
+		if window.End, err = audio.ParseTimestamp(endRaw); err != nil {
+			return nil, fmt.Errorf("end: %w", err)
+		}
+		if window.End <= window.Start {
+			return nil, errors.New("end must be after start")
+		}
+	}
+	return []audio.TimeRange{window}, nil
+}
+
+// parseChannelLabels parses "agent,customer" into one speaker label per
+// channel, in channel order.
+func parseChannelLabels(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var labels []string
+	for _, part := range strings.Split(value, ",") {
+		label := strings.TrimSpace(part)
+		if label == "" {
+			return nil, fmt.Errorf("channel-labels %q contains an empty label", value)
+		}
+		if slices.Contains(labels, label) {
+			return nil, fmt.Errorf("channel-labels %q repeats label %q", value, label)
+		}
+		labels = append(labels, label)
+	}
+	if len(labels) < 2 {
+		return nil, errors.New("channel-labels needs a label for at least two channels")
+	}
+	return labels, nil
+}
+
 func chooseString(override StringOverride, env EnvSource, envKey string, fallback string) string {
 	if override.Provided {
 		return strings.TrimSpace(override.Value)
@@ -162,6 +549,19 @@ func chooseString(override StringOverride, env EnvSource, envKey string, fallbac
 	return fallback
 }
 
+func chooseBool(override BoolOverride, env EnvSource, envKey string, fallback bool) bool {
+	if override.Provided {
+		return override.Value
+	}
+	if value, ok := env.LookupEnv(envKey); ok && strings.TrimSpace(value) != "" {
+		parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
 func chooseInt(override IntOverride, env EnvSource, envKey string, fallback int) int {
 	if override.Provided {
 		return override.Value
@@ -179,7 +579,10 @@ func defaultModelForProvider(providerName string) string {
 	switch domain.Provider(strings.ToLower(strings.TrimSpace(providerName))) {
 	case domain.ProviderGroq:
 		return "whisper-large-v3-turbo"
-	default:
+	case domain.ProviderOpenAI, domain.ProviderOpenRouter:
 		return "gpt-4o-transcribe"
+	default:
+		// Declared OpenAI-compatible providers pick their model at run time.
+		return ""
 	}
 }
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/arykalin/whisper-cli/internal/audio"
 	"github.com/arykalin/whisper-cli/internal/domain"
 )
 
@@ -136,3 +139,244 @@ func TestResolveRejectsInvalidChunkSeconds(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestResolveAcceptsDeclaredProviderWithoutDefaultModel(t *testing.T) {
+	t.Parallel()
+
+	overrides := Overrides{}
+	overrides.Input.SetValue("input.m4a")
+	overrides.Provider.SetValue("LocalAI")
+
+	cfg, err := Resolve(overrides, mapEnv{})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if cfg.Provider != domain.Provider("localai") {
+		t.Fatalf("provider = %s, want localai", cfg.Provider)
+	}
+	if cfg.Model != "" {
+		t.Fatalf("model = %q, want empty", cfg.Model)
+	}
+
+	overrides.Provider.SetValue("local ai")
+	if _, err := Resolve(overrides, mapEnv{}); err == nil || !strings.Contains(err.Error(), "unsupported provider") {
+		t.Fatalf("expected unsupported provider error, got %v", err)
+	}
+}
+
+func TestResolveParsesTask(t *testing.T) {
+	t.Parallel()
+
+	overrides := Overrides{}
+	overrides.Input.SetValue("input.m4a")
+
+	cfg, err := Resolve(overrides, mapEnv{"WHISPER_CLI_TASK": "Translate"})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if cfg.Task != domain.TaskTranslate {
+		t.Fatalf("task = %s, want translate", cfg.Task)
+	}
+
+	overrides.Task.SetValue("summarize")
+	if _, err := Resolve(overrides, mapEnv{}); err == nil || !strings.Contains(err.Error(), "unsupported task") {
+		t.Fatalf("expected unsupported task error, got %v", err)
+	}
+}
+
+func TestResolveRejectsInvalidChunkOverlap(t *testing.T) {
+	t.Parallel()
+
+	overrides := Overrides{}
+	overrides.Input.SetValue("input.m4a")
+	overrides.ChunkSeconds.SetValue(60)
+	overrides.ChunkOverlap.SetValue(60)
+
+	if _, err := Resolve(overrides, mapEnv{}); err == nil || !strings.Contains(err.Error(), "chunk-overlap") {
+		t.Fatalf("expected chunk-overlap error, got %v", err)
+	}
+
+	overrides.ChunkOverlap.SetValue(5)
+	overrides.ChunkMode.SetValue("silence")
+	if _, err := Resolve(overrides, mapEnv{}); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Fatalf("expected combination error, got %v", err)
+	}
+}
+
+func TestResolveParsesAudioProfile(t *testing.T) {
+	t.Parallel()
+
+	overrides := Overrides{}
+	overrides.Input.SetValue("input.m4a")
+
+	cfg, err := Resolve(overrides, mapEnv{})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if cfg.AudioProfile != "" {
+		t.Fatalf("audio profile = %q, want empty for provider default", cfg.AudioProfile)
+	}
+
+	cfg, err = Resolve(overrides, mapEnv{"WHISPER_CLI_AUDIO_PROFILE": "speech-opus"})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if cfg.AudioProfile != audio.ProfileSpeechOpus {
+		t.Fatalf("audio profile = %q, want speech-opus", cfg.AudioProfile)
+	}
+
+	overrides.AudioProfile.SetValue("wav")
+	if _, err := Resolve(overrides, mapEnv{}); err == nil || !strings.Contains(err.Error(), "unsupported audio profile") {
+		t.Fatalf("expected unsupported audio profile error, got %v", err)
+	}
+}
+
+func TestResolveValidatesSpeed(t *testing.T) {
+	t.Parallel()
+
+	overrides := Overrides{}
+	overrides.Input.SetValue("input.m4a")
+
+	cfg, err := Resolve(overrides, mapEnv{"WHISPER_CLI_SPEED": "1.5"})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if cfg.Speed != 1.5 {
+		t.Fatalf("speed = %v, want 1.5", cfg.Speed)
+	}
+
+	for _, value := range []string{"1.1", "2.5", "fast"} {
+		overrides.Speed.SetValue(value)
+		if _, err := Resolve(overrides, mapEnv{}); err == nil || !strings.Contains(err.Error(), "speed must be") {
+			t.Fatalf("Resolve(speed=%s) error = %v, want speed error", value, err)
+		}
+	}
+}
+
+func TestResolveParsesChannelLabels(t *testing.T) {
+	t.Parallel()
+
+	overrides := Overrides{}
+	overrides.Input.SetValue("input.m4a")
+	overrides.ChannelLabels.SetValue("agent, customer")
+
+	cfg, err := Resolve(overrides, mapEnv{})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if strings.Join(cfg.ChannelLabels, "|") != "agent|customer" {
+		t.Fatalf("channel labels = %#v", cfg.ChannelLabels)
+	}
+
+	for _, value := range []string{"agent", "agent,,customer", "agent,agent"} {
+		overrides.ChannelLabels.SetValue(value)
+		if _, err := Resolve(overrides, mapEnv{}); err == nil || !strings.Contains(err.Error(), "channel-labels") {
+			t.Fatalf("Resolve(channel-labels=%s) error = %v", value, err)
+		}
+	}
+}
+
+func TestResolveParsesTimeRanges(t *testing.T) {
+	t.Parallel()
+
+	overrides := Overrides{}
+	overrides.Input.SetValue("input.m4a")
+	overrides.Start.SetValue("1:00:00")
+	overrides.End.SetValue("1:30:00")
+
+	cfg, err := Resolve(overrides, mapEnv{})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if len(cfg.Ranges) != 1 || cfg.Ranges[0].Start != 3600 || cfg.Ranges[0].End != 5400 {
+		t.Fatalf("ranges = %#v", cfg.Ranges)
+	}
+
+	if _, err := Resolve(overrides, mapEnv{"WHISPER_CLI_RANGES": "0-60"}); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Fatalf("expected ranges/start conflict, got %v", err)
+	}
+
+	overrides.End.SetValue("30:00")
+	if _, err := Resolve(overrides, mapEnv{}); err == nil || !strings.Contains(err.Error(), "end must be after start") {
+		t.Fatalf("expected end before start error, got %v", err)
+	}
+}
+
+func TestParseScanOptions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	scan, err := parseScanOptions(true, "*.mp3, talks/*", "drafts", "10M", "48h", now)
+	if err != nil {
+		t.Fatalf("parseScanOptions returned error: %v", err)
+	}
+	if !scan.Recursive || len(scan.Include) != 2 || scan.Exclude[0] != "drafts" {
+		t.Fatalf("scan = %#v", scan)
+	}
+	if scan.MinSize != 10<<20 {
+		t.Fatalf("min size = %d, want %d", scan.MinSize, 10<<20)
+	}
+	if !scan.ModifiedSince.Equal(now.Add(-48 * time.Hour)) {
+		t.Fatalf("modified since = %s", scan.ModifiedSince)
+	}
+
+	if _, err := parseScanOptions(false, "[", "", "", "", now); err == nil {
+		t.Fatalf("expected bad pattern error")
+	}
+	if _, err := parseScanOptions(false, "", "", "10X", "", now); err == nil {
+		t.Fatalf("expected invalid size error")
+	}
+	if _, err := parseScanOptions(false, "", "", "", "yesterday", now); err == nil {
+		t.Fatalf("expected invalid modified-since error")
+	}
+}
+
+func TestResolveRejectsInputWithInputList(t *testing.T) {
+	t.Parallel()
+
+	_, err := Resolve(Overrides{}, mapEnv{
+		"WHISPER_CLI_INPUT":      "lecture.mp3",
+		"WHISPER_CLI_INPUT_LIST": "files.txt",
+	})
+	if err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Fatalf("expected input/input-list conflict, got %v", err)
+	}
+}
+
+func TestResolveUsesXDGCacheDirWhenCacheEnabled(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Resolve(Overrides{}, mapEnv{
+		"WHISPER_CLI_INPUT": "lecture.mp3",
+		"WHISPER_CLI_CACHE": "true",
+		"XDG_CACHE_HOME":    "/var/cache/me",
+	})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if !cfg.Cache || cfg.CacheDir != filepath.Join("/var/cache/me", "whisper-cli") {
+		t.Fatalf("cache = %v, cache dir = %q", cfg.Cache, cfg.CacheDir)
+	}
+}
+
+func TestResolveWatchPrecedenceAndValidation(t *testing.T) {
+	t.Parallel()
+
+	overrides := WatchOverrides{}
+	overrides.FileJobs.SetValue(4)
+	opts, err := ResolveWatch(overrides, mapEnv{
+		"WHISPER_CLI_WATCH_DEBOUNCE":  "500ms",
+		"WHISPER_CLI_WATCH_FILE_JOBS": "9",
+		"WHISPER_CLI_WATCH_DONE_DIR":  "done",
+	})
+	if err != nil {
+		t.Fatalf("ResolveWatch returned error: %v", err)
+	}
+	if opts.Debounce != 500*time.Millisecond || opts.FileJobs != 4 || opts.DoneDir != "done" || opts.FailedDir != "" {
+		t.Fatalf("opts = %#v", opts)
+	}
+
+	if _, err := ResolveWatch(WatchOverrides{}, mapEnv{"WHISPER_CLI_WATCH_DEBOUNCE": "soon"}); err == nil {
+		t.Fatal("expected invalid debounce error")
+	}
+}
@@ -14,6 +14,44 @@ const (
 	ProviderOpenRouter Provider = "openrouter"
 )
 
+// ValidProviderName reports whether name can be used as a provider identifier:
+// lowercase ASCII letters, digits, '-' and '_', starting with a letter or digit.
+func ValidProviderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for idx, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		case (r == '-' || r == '_') && idx > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type Task string
+
+const (
+	TaskTranscribe Task = "transcribe"
+	TaskTranslate  Task = "translate"
+)
+
+// TranslationTargetLanguage is the only target the audio translations endpoint produces.
+const TranslationTargetLanguage = "en"
+
+func ParseTask(value string) (Task, error) {
+	switch task := Task(strings.ToLower(strings.TrimSpace(value))); task {
+	case "", TaskTranscribe:
+		return TaskTranscribe, nil
+	case TaskTranslate:
+		return task, nil
+	default:
+		return "", fmt.Errorf("unsupported task %q", value)
+	}
+}
+
 type ArtifactKind string
 
 const (
@@ -22,6 +60,7 @@ const (
 	ArtifactVTT        ArtifactKind = "vtt"
 	ArtifactDiarized   ArtifactKind = "diarized"
 	ArtifactRaw        ArtifactKind = "raw"
+	ArtifactWords      ArtifactKind = "words"
 )
 
 var knownArtifacts = map[ArtifactKind]struct{}{
@@ -30,6 +69,7 @@ var knownArtifacts = map[ArtifactKind]struct{}{
 	ArtifactVTT:        {},
 	ArtifactDiarized:   {},
 	ArtifactRaw:        {},
+	ArtifactWords:      {},
 }
 
 func KnownArtifacts() []ArtifactKind {
@@ -85,12 +125,20 @@ func (a ArtifactSet) Sorted() []ArtifactKind {
 }
 
 type Capabilities struct {
-	SupportsPrompt            bool
-	SupportsSegmentTimestamps bool
-	SupportsWordTimestamps    bool
-	SupportsSRT               bool
-	SupportsVTT               bool
-	SupportsDiarization       bool
+	SupportsPrompt            bool `json:"prompt,omitempty"`
+	SupportsSegmentTimestamps bool `json:"segment_timestamps,omitempty"`
+	SupportsWordTimestamps    bool `json:"word_timestamps,omitempty"`
+	SupportsSRT               bool `json:"srt,omitempty"`
+	SupportsVTT               bool `json:"vtt,omitempty"`
+	SupportsDiarization       bool `json:"diarization,omitempty"`
+	SupportsTranslation       bool `json:"translation,omitempty"`
+	// MaxUploadBytes is the largest file the endpoint accepts; zero means unknown.
+	MaxUploadBytes int64 `json:"max_upload_bytes,omitempty"`
+	// InputFormats lists the file extensions, without the dot, the endpoint
+	// accepts; empty means any.
+	InputFormats []string `json:"input_formats,omitempty"`
+	// AudioProfile is the preprocessing profile used when none is requested.
+	AudioProfile string `json:"audio_profile,omitempty"`
 }
 
 type Transcript struct {
@@ -98,15 +146,41 @@ type Transcript struct {
 	Model           string           `json:"model"`
 	Source          string           `json:"source,omitempty"`
 	Language        string           `json:"language,omitempty"`
+	Translated      bool             `json:"translated,omitempty"`
+	SourceLanguage  string           `json:"source_language,omitempty"`
+	TargetLanguage  string           `json:"target_language,omitempty"`
+	Speed           float64          `json:"speed,omitempty"`
+	AudioFilter     string           `json:"audio_filter,omitempty"`
 	Text            string           `json:"text"`
 	Segments        []Segment        `json:"segments,omitempty"`
 	SpeakerSegments []SpeakerSegment `json:"speaker_segments,omitempty"`
+	Words           []Word           `json:"words,omitempty"`
+	// FailedChunks lists the chunks missing from a transcript written with
+	// --allow-partial; their place in the text is marked as a gap.
+	FailedChunks []FailedChunk `json:"failed_chunks,omitempty"`
+}
+
+// FailedChunk is a chunk whose transcription failed, with its time range on
+// the input timeline.
+type FailedChunk struct {
+	Channel string  `json:"channel,omitempty"`
+	Number  int     `json:"number"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Error   string  `json:"error"`
 }
 
 type Segment struct {
 	Start float64 `json:"start"`
 	End   float64 `json:"end"`
 	Text  string  `json:"text"`
+	Words []Word  `json:"words,omitempty"`
+}
+
+type Word struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Word  string  `json:"word"`
 }
 
 type SpeakerSegment struct {
@@ -127,6 +201,23 @@ func ShiftSegments(src []Segment, offset float64) []Segment {
 			Start: segment.Start + offset,
 			End:   segment.End + offset,
 			Text:  segment.Text,
+			Words: ShiftWords(segment.Words, offset),
+		})
+	}
+	return shifted
+}
+
+func ShiftWords(src []Word, offset float64) []Word {
+	if len(src) == 0 {
+		return nil
+	}
+
+	shifted := make([]Word, 0, len(src))
+	for _, word := range src {
+		shifted = append(shifted, Word{
+			Start: word.Start + offset,
+			End:   word.End + offset,
+			Word:  word.Word,
 		})
 	}
 	return shifted
@@ -149,6 +240,58 @@ func ShiftSpeakerSegments(src []SpeakerSegment, offset float64) []SpeakerSegment
 	return shifted
 }
 
+// RetimeSegments maps segment and word boundaries onto another timeline, such
+// as from a compacted or sped-up source back to the original input.
+func RetimeSegments(src []Segment, start func(float64) float64, end func(float64) float64) []Segment {
+	if len(src) == 0 {
+		return nil
+	}
+
+	retimed := make([]Segment, 0, len(src))
+	for _, segment := range src {
+		retimed = append(retimed, Segment{
+			Start: start(segment.Start),
+			End:   end(segment.End),
+			Text:  segment.Text,
+			Words: RetimeWords(segment.Words, start, end),
+		})
+	}
+	return retimed
+}
+
+func RetimeWords(src []Word, start func(float64) float64, end func(float64) float64) []Word {
+	if len(src) == 0 {
+		return nil
+	}
+
+	retimed := make([]Word, 0, len(src))
+	for _, word := range src {
+		retimed = append(retimed, Word{
+			Start: start(word.Start),
+			End:   end(word.End),
+			Word:  word.Word,
+		})
+	}
+	return retimed
+}
+
+func RetimeSpeakerSegments(src []SpeakerSegment, start func(float64) float64, end func(float64) float64) []SpeakerSegment {
+	if len(src) == 0 {
+		return nil
+	}
+
+	retimed := make([]SpeakerSegment, 0, len(src))
+	for _, segment := range src {
+		retimed = append(retimed, SpeakerSegment{
+			Start:   start(segment.Start),
+			End:     end(segment.End),
+			Speaker: segment.Speaker,
+			Text:    segment.Text,
+		})
+	}
+	return retimed
+}
+
 func (t Transcript) PlainText() string {
 	if strings.TrimSpace(t.Text) != "" {
 		return strings.TrimSpace(t.Text)
@@ -0,0 +1,25 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+)
+
+// WriteWords writes the word-level timeline to words.json.
+func WriteWords(fs fsx.FS, dir string, words []domain.Word) error {
+	if words == nil {
+		words = []domain.Word{}
+	}
+	data, err := json.MarshalIndent(words, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal words artifact: %w", err)
+	}
+	if err := fs.WriteFile(filepath.Join(dir, "words.json"), data, 0o644); err != nil {
+		return fmt.Errorf("write words artifact: %w", err)
+	}
+	return nil
+}
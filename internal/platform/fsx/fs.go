@@ -19,6 +19,9 @@ type FS interface {
 	WriteFile(path string, data []byte, perm os.FileMode) error
 	MkdirAll(path string, perm os.FileMode) error
 	Open(path string) (ReadSeekCloser, error)
+	Create(path string) (io.WriteCloser, error)
+	Remove(path string) error
+	Rename(oldPath string, newPath string) error
 }
 
 type OS struct{}
@@ -50,3 +53,15 @@ func (OS) MkdirAll(path string, perm os.FileMode) error {
 func (OS) Open(path string) (ReadSeekCloser, error) {
 	return os.Open(path)
 }
+
+func (OS) Create(path string) (io.WriteCloser, error) {
+	return os.Create(path)
+}
+
+func (OS) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (OS) Rename(oldPath string, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
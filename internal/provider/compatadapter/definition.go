@@ -0,0 +1,84 @@
+package compatadapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/arykalin/whisper-cli/internal/domain"
+)
+
+// Definition declares one OpenAI-compatible transcription endpoint.
+type Definition struct {
+	Name      string                         `json:"name"`
+	BaseURL   string                         `json:"base_url"`
+	APIKeyEnv string                         `json:"api_key_env,omitempty"`
+	Models    map[string]domain.Capabilities `json:"models"`
+}
+
+type definitionFile struct {
+	Providers []Definition `json:"providers"`
+}
+
+var reservedNames = map[domain.Provider]struct{}{
+	domain.ProviderOpenAI:     {},
+	domain.ProviderGroq:       {},
+	domain.ProviderOpenRouter: {},
+}
+
+// ParseDefinitions decodes a provider definition document of the form
+// {"providers": [{"name": ..., "base_url": ..., "models": {...}}]}.
+func ParseDefinitions(data []byte) ([]Definition, error) {
+	var file definitionFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("decode provider definitions: %w", err)
+	}
+
+	seen := map[string]struct{}{}
+	for idx := range file.Providers {
+		def := &file.Providers[idx]
+		def.Name = strings.ToLower(strings.TrimSpace(def.Name))
+		def.BaseURL = strings.TrimSpace(def.BaseURL)
+		def.APIKeyEnv = strings.TrimSpace(def.APIKeyEnv)
+
+		if err := def.validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := seen[def.Name]; ok {
+			return nil, fmt.Errorf("provider %s is declared more than once", def.Name)
+		}
+		seen[def.Name] = struct{}{}
+
+		if !strings.HasSuffix(def.BaseURL, "/") {
+			def.BaseURL += "/"
+		}
+	}
+	return file.Providers, nil
+}
+
+func (d Definition) validate() error {
+	if !domain.ValidProviderName(d.Name) {
+		return fmt.Errorf("invalid provider name %q", d.Name)
+	}
+	if _, ok := reservedNames[domain.Provider(d.Name)]; ok {
+		return fmt.Errorf("provider name %s is reserved for a built-in provider", d.Name)
+	}
+
+	parsed, err := url.Parse(d.BaseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("provider %s: base_url must be an absolute http(s) URL", d.Name)
+	}
+	if len(d.Models) == 0 {
+		return fmt.Errorf("provider %s: at least one model is required", d.Name)
+	}
+	for model := range d.Models {
+		if strings.TrimSpace(model) == "" {
+			return fmt.Errorf("provider %s: model name must not be empty", d.Name)
+		}
+	}
+	return nil
+}
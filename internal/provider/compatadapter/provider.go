@@ -0,0 +1,161 @@
+package compatadapter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+	"github.com/arykalin/whisper-cli/internal/provider"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/packages/param"
+	"github.com/openai/openai-go/shared/constant"
+	"github.com/rs/zerolog"
+)
+
+type requester interface {
+	Transcribe(ctx context.Context, params openai.AudioTranscriptionNewParams) (*openai.Transcription, error)
+	Translate(ctx context.Context, params openai.AudioTranslationNewParams) (*openai.Translation, error)
+}
+
+type serviceRequester struct {
+	service      openai.AudioTranscriptionService
+	translations openai.AudioTranslationService
+}
+
+func (s serviceRequester) Transcribe(ctx context.Context, params openai.AudioTranscriptionNewParams) (*openai.Transcription, error) {
+	return s.service.New(ctx, params)
+}
+
+func (s serviceRequester) Translate(ctx context.Context, params openai.AudioTranslationNewParams) (*openai.Translation, error) {
+	return s.translations.New(ctx, params)
+}
+
+// Provider talks to any endpoint that implements the OpenAI
+// /audio/transcriptions contract, such as whisper.cpp server, LocalAI or vLLM.
+type Provider struct {
+	def       Definition
+	apiKey    string
+	fs        fsx.FS
+	requester requester
+	logger    zerolog.Logger
+}
+
+func New(def Definition, apiKey string, fs fsx.FS, logger zerolog.Logger) *Provider {
+	opts := []option.RequestOption{option.WithBaseURL(def.BaseURL)}
+	if apiKey != "" {
+		opts = append(opts, option.WithAPIKey(apiKey))
+	} else {
+		// The SDK picks up OPENAI_API_KEY by default; keyless local servers must not receive it.
+		opts = append(opts, option.WithHeaderDel("authorization"))
+	}
+
+	client := openai.NewClient(opts...)
+	return newWithRequester(def, apiKey, fs, logger, serviceRequester{service: client.Audio.Transcriptions, translations: client.Audio.Translations})
+}
+
+func newWithRequester(def Definition, apiKey string, fs fsx.FS, logger zerolog.Logger, requester requester) *Provider {
+	return &Provider{
+		def:       def,
+		apiKey:    apiKey,
+		fs:        fs,
+		requester: requester,
+		logger:    logger.With().Str("provider", def.Name).Logger(),
+	}
+}
+
+func (p *Provider) Name() domain.Provider {
+	return domain.Provider(p.def.Name)
+}
+
+func (p *Provider) Preflight() error {
+	if p.def.APIKeyEnv != "" && p.apiKey == "" {
+		return fmt.Errorf("%s is not set in process environment; run `export %s=...` or prefix the command with `%s=...`", p.def.APIKeyEnv, p.def.APIKeyEnv, p.def.APIKeyEnv)
+	}
+	return nil
+}
+
+func (p *Provider) Capabilities(model string) (domain.Capabilities, bool) {
+	caps, ok := p.def.Models[model]
+	return caps, ok
+}
+
+func (p *Provider) SupportedModels() []string {
+	models := make([]string, 0, len(p.def.Models))
+	for model := range p.def.Models {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+func (p *Provider) Transcribe(ctx context.Context, req provider.Request) (provider.Response, error) {
+	caps, ok := p.Capabilities(req.Model)
+	if !ok {
+		return provider.Response{}, fmt.Errorf("model %s is not supported by provider %s", req.Model, p.Name())
+	}
+
+	var (
+		raw  []byte
+		text string
+	)
+
+	err := provider.Retry(ctx, p.logger, p.def.Name, func() (err error) {
+		file, err := p.fs.Open(req.FilePath)
+		if err != nil {
+			return fmt.Errorf("open audio file: %w", err)
+		}
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("close audio file: %w", closeErr)
+			}
+		}()
+
+		if req.Task == domain.TaskTranslate {
+			text, raw, err = provider.Translate(ctx, p.requester, file, req, caps)
+			return err
+		}
+
+		params := openai.AudioTranscriptionNewParams{
+			File:     file,
+			Model:    req.Model,
+			Language: param.NewOpt(req.Language),
+		}
+		if req.Prompt != "" && caps.SupportsPrompt {
+			params.Prompt = param.NewOpt(req.Prompt)
+		}
+
+		switch {
+		case req.WantDiarization && caps.SupportsDiarization:
+			params.ResponseFormat = openai.AudioResponseFormat("diarized_json")
+			params.ChunkingStrategy = openai.AudioTranscriptionNewParamsChunkingStrategyUnion{
+				OfAuto: constant.ValueOf[constant.Auto](),
+			}
+		case caps.SupportsSegmentTimestamps:
+			params.ResponseFormat = openai.AudioResponseFormatVerboseJSON
+			params.TimestampGranularities = provider.TimestampGranularities(req, caps)
+		default:
+			params.ResponseFormat = openai.AudioResponseFormatJSON
+		}
+
+		resp, err := p.requester.Transcribe(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		text = resp.Text
+		raw = []byte(resp.RawJSON())
+		return nil
+	})
+	if err != nil {
+		return provider.Response{}, err
+	}
+
+	transcript := provider.ParseOpenAICompatibleTranscript(p.Name(), req.Model, raw, text)
+	return provider.Response{
+		Transcript: transcript,
+		Raw:        raw,
+	}, nil
+}
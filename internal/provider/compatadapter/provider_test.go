@@ -0,0 +1,196 @@
+package compatadapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/arykalin/whisper-cli/internal/platform/fsx"
+	"github.com/arykalin/whisper-cli/internal/provider"
+	"github.com/openai/openai-go"
+	"github.com/rs/zerolog"
+)
+
+type fakeRequester struct {
+	responses  []string
+	lastParams []openai.AudioTranscriptionNewParams
+}
+
+func (f *fakeRequester) Transcribe(ctx context.Context, params openai.AudioTranscriptionNewParams) (*openai.Transcription, error) {
+	f.lastParams = append(f.lastParams, params)
+	var transcription openai.Transcription
+	if err := json.Unmarshal([]byte(f.responses[0]), &transcription); err != nil {
+		return nil, err
+	}
+	f.responses = f.responses[1:]
+	return &transcription, nil
+}
+
+func (f *fakeRequester) Translate(context.Context, openai.AudioTranslationNewParams) (*openai.Translation, error) {
+	return nil, errors.New("unexpected translation request")
+}
+
+const testDefinitions = `{
+  "providers": [
+    {
+      "name": "WhisperCpp",
+      "base_url": "http://127.0.0.1:8080/v1",
+      "api_key_env": "WHISPERCPP_API_KEY",
+      "models": {
+        "whisper-large-v3": {"prompt": true, "segment_timestamps": true, "srt": true, "vtt": true},
+        "tiny": {}
+      }
+    }
+  ]
+}`
+
+func TestParseDefinitionsNormalizesFields(t *testing.T) {
+	t.Parallel()
+
+	defs, err := ParseDefinitions([]byte(testDefinitions))
+	if err != nil {
+		t.Fatalf("ParseDefinitions returned error: %v", err)
+	}
+	if len(defs) != 1 {
+		t.Fatalf("definitions = %#v", defs)
+	}
+
+	def := defs[0]
+	if def.Name != "whispercpp" {
+		t.Fatalf("name = %s, want whispercpp", def.Name)
+	}
+	if def.BaseURL != "http://127.0.0.1:8080/v1/" {
+		t.Fatalf("base_url = %s", def.BaseURL)
+	}
+	caps := def.Models["whisper-large-v3"]
+	if !caps.SupportsPrompt || !caps.SupportsSegmentTimestamps || !caps.SupportsSRT || !caps.SupportsVTT || caps.SupportsDiarization {
+		t.Fatalf("capabilities = %#v", caps)
+	}
+}
+
+func TestParseDefinitionsRejectsInvalidDefinitions(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		document string
+		want     string
+	}{
+		"reserved name": {
+			document: `{"providers":[{"name":"openai","base_url":"http://localhost/v1","models":{"m":{}}}]}`,
+			want:     "reserved",
+		},
+		"invalid name": {
+			document: `{"providers":[{"name":"my provider","base_url":"http://localhost/v1","models":{"m":{}}}]}`,
+			want:     "invalid provider name",
+		},
+		"relative url": {
+			document: `{"providers":[{"name":"local","base_url":"localhost/v1","models":{"m":{}}}]}`,
+			want:     "base_url",
+		},
+		"no models": {
+			document: `{"providers":[{"name":"local","base_url":"http://localhost/v1"}]}`,
+			want:     "at least one model",
+		},
+		"duplicate": {
+			document: `{"providers":[{"name":"local","base_url":"http://a/v1","models":{"m":{}}},{"name":"local","base_url":"http://b/v1","models":{"m":{}}}]}`,
+			want:     "more than once",
+		},
+		"unknown capability": {
+			document: `{"providers":[{"name":"local","base_url":"http://a/v1","models":{"m":{"timestamps":true}}}]}`,
+			want:     "unknown field",
+		},
+	} {
+		_, err := ParseDefinitions([]byte(tc.document))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: err = %v, want substring %q", name, err, tc.want)
+		}
+	}
+}
+
+func TestProviderUsesDeclaredCapabilities(t *testing.T) {
+	t.Parallel()
+
+	defs, err := ParseDefinitions([]byte(testDefinitions))
+	if err != nil {
+		t.Fatalf("ParseDefinitions returned error: %v", err)
+	}
+
+	dir := t.TempDir()
+	audioPath := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(audioPath, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write audio: %v", err)
+	}
+
+	requester := &fakeRequester{
+		responses: []string{
+			`{"text":"hello","language":"en","segments":[{"start":0,"end":1,"text":"hello"}]}`,
+			`{"text":"plain"}`,
+		},
+	}
+	client := newWithRequester(defs[0], "key", fsx.OS{}, zerolog.New(io.Discard), requester)
+	if client.Name() != domain.Provider("whispercpp") {
+		t.Fatalf("name = %s", client.Name())
+	}
+	if strings.Join(client.SupportedModels(), ",") != "tiny,whisper-large-v3" {
+		t.Fatalf("supported models = %v", client.SupportedModels())
+	}
+
+	response, err := client.Transcribe(context.Background(), provider.Request{
+		FilePath: audioPath,
+		Model:    "whisper-large-v3",
+		Language: "en",
+		Prompt:   "names",
+	})
+	if err != nil {
+		t.Fatalf("Transcribe returned error: %v", err)
+	}
+	if response.Transcript.Provider != "whispercpp" || len(response.Transcript.Segments) != 1 {
+		t.Fatalf("transcript = %#v", response.Transcript)
+	}
+	params := requester.lastParams[0]
+	if params.ResponseFormat != openai.AudioResponseFormatVerboseJSON {
+		t.Fatalf("responseFormat = %s", params.ResponseFormat)
+	}
+	if strings.Join(params.TimestampGranularities, ",") != "segment" {
+		t.Fatalf("timestamp granularities = %v", params.TimestampGranularities)
+	}
+	if params.Prompt.Value != "names" {
+		t.Fatalf("prompt = %q", params.Prompt.Value)
+	}
+
+	if _, err := client.Transcribe(context.Background(), provider.Request{
+		FilePath: audioPath,
+		Model:    "tiny",
+		Prompt:   "names",
+	}); err != nil {
+		t.Fatalf("Transcribe returned error: %v", err)
+	}
+	params = requester.lastParams[1]
+	if params.ResponseFormat != openai.AudioResponseFormatJSON {
+		t.Fatalf("responseFormat = %s", params.ResponseFormat)
+	}
+	if params.Prompt.Valid() {
+		t.Fatalf("prompt should not be sent to a model without prompt support")
+	}
+}
+
+func TestProviderPreflightRequiresDeclaredKey(t *testing.T) {
+	t.Parallel()
+
+	def := Definition{Name: "local", BaseURL: "http://localhost/v1/", APIKeyEnv: "LOCAL_KEY"}
+	client := New(def, "", fsx.OS{}, zerolog.New(io.Discard))
+	if err := client.Preflight(); err == nil || !strings.Contains(err.Error(), "export LOCAL_KEY") {
+		t.Fatalf("expected preflight error for missing key, got %v", err)
+	}
+
+	def.APIKeyEnv = ""
+	if err := New(def, "", fsx.OS{}, zerolog.New(io.Discard)).Preflight(); err != nil {
+		t.Fatalf("keyless provider preflight returned error: %v", err)
+	}
+}
@@ -19,16 +19,22 @@ const baseURL = "https://api.groq.com/openai/v1/"
 
 type requester interface {
 	Transcribe(ctx context.Context, params openai.AudioTranscriptionNewParams) (*openai.Transcription, error)
+	Translate(ctx context.Context, params openai.AudioTranslationNewParams) (*openai.Translation, error)
 }
 
 type serviceRequester struct {
-	service openai.AudioTranscriptionService
+	service      openai.AudioTranscriptionService
+	translations openai.AudioTranslationService
 }
 
 func (s serviceRequester) Transcribe(ctx context.Context, params openai.AudioTranscriptionNewParams) (*openai.Transcription, error) {
 	return s.service.New(ctx, params)
 }
 
+func (s serviceRequester) Translate(ctx context.Context, params openai.AudioTranslationNewParams) (*openai.Translation, error) {
+	return s.translations.New(ctx, params)
+}
+
 type Provider struct {
 	apiKey    string
 	fs        fsx.FS
@@ -46,7 +52,7 @@ func New(apiKey string, fs fsx.FS, logger zerolog.Logger) *Provider {
 	return &Provider{
 		apiKey:    apiKey,
 		fs:        fs,
-		requester: serviceRequester{service: client.Audio.Transcriptions},
+		requester: serviceRequester{service: client.Audio.Transcriptions, translations: client.Audio.Translations},
 		logger:    logger.With().Str("provider", string(domain.ProviderGroq)).Logger(),
 	}
 }
@@ -98,6 +104,11 @@ func (p *Provider) Transcribe(ctx context.Context, req provider.Request) (provid
 			}
 		}()
 
+		if req.Task == domain.TaskTranslate {
+			text, raw, err = provider.Translate(ctx, p.requester, file, req, caps)
+			return err
+		}
+
 		params := openai.AudioTranscriptionNewParams{
 			File:           file,
 			Model:          req.Model,
@@ -108,7 +119,7 @@ func (p *Provider) Transcribe(ctx context.Context, req provider.Request) (provid
 			params.Prompt = param.NewOpt(req.Prompt)
 		}
 		if caps.SupportsSegmentTimestamps {
-			params.TimestampGranularities = []string{"segment"}
+			params.TimestampGranularities = provider.TimestampGranularities(req, caps)
 		}
 
 		resp, err := p.requester.Transcribe(ctx, params)
@@ -131,17 +142,32 @@ func (p *Provider) Transcribe(ctx context.Context, req provider.Request) (provid
 	}, nil
 }
 
+// maxUploadBytes is the Groq audio endpoint file size limit on the free tier.
+const maxUploadBytes = 25 << 20
+
+// inputFormats are the upload formats the Groq audio endpoint accepts.
+var inputFormats = []string{"flac", "m4a", "mp3", "mp4", "mpeg", "mpga", "ogg", "wav", "webm"}
+
 var capabilities = map[string]domain.Capabilities{
 	"whisper-large-v3": {
 		SupportsPrompt:            true,
 		SupportsSegmentTimestamps: true,
+		SupportsWordTimestamps:    true,
 		SupportsSRT:               true,
 		SupportsVTT:               true,
+		SupportsTranslation:       true,
+		MaxUploadBytes:            maxUploadBytes,
+		InputFormats:              inputFormats,
+		AudioProfile:              "speech-flac",
 	},
 	"whisper-large-v3-turbo": {
 		SupportsPrompt:            true,
 		SupportsSegmentTimestamps: true,
+		SupportsWordTimestamps:    true,
 		SupportsSRT:               true,
 		SupportsVTT:               true,
+		MaxUploadBytes:            maxUploadBytes,
+		InputFormats:              inputFormats,
+		AudioProfile:              "speech-flac",
 	},
 }
@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
 	"github.com/rs/zerolog"
 )
 
@@ -16,6 +20,7 @@ type responsePayload struct {
 	Text     string           `json:"text"`
 	Language string           `json:"language"`
 	Segments []segmentPayload `json:"segments"`
+	Words    []wordPayload    `json:"words"`
 }
 
 type segmentPayload struct {
@@ -25,6 +30,12 @@ type segmentPayload struct {
 	Speaker string  `json:"speaker"`
 }
 
+type wordPayload struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Word  string  `json:"word"`
+}
+
 type statusCoder interface {
 	StatusCode() int
 }
@@ -50,6 +61,17 @@ func ParseOpenAICompatibleTranscript(providerName domain.Provider, model string,
 	}
 	transcript.Language = strings.TrimSpace(payload.Language)
 
+	for _, word := range payload.Words {
+		if strings.TrimSpace(word.Word) == "" {
+			continue
+		}
+		transcript.Words = append(transcript.Words, domain.Word{
+			Start: word.Start,
+			End:   word.End,
+			Word:  strings.TrimSpace(word.Word),
+		})
+	}
+
 	for _, segment := range payload.Segments {
 		if strings.TrimSpace(segment.Text) == "" {
 			continue
@@ -58,6 +80,7 @@ func ParseOpenAICompatibleTranscript(providerName domain.Provider, model string,
 			Start: segment.Start,
 			End:   segment.End,
 			Text:  strings.TrimSpace(segment.Text),
+			Words: wordsWithin(transcript.Words, segment.Start, segment.End),
 		})
 		if strings.TrimSpace(segment.Speaker) != "" {
 			transcript.SpeakerSegments = append(transcript.SpeakerSegments, domain.SpeakerSegment{
@@ -76,6 +99,26 @@ func ParseOpenAICompatibleTranscript(providerName domain.Provider, model string,
 	return transcript
 }
 
+// wordsWithin returns the words that start inside [start, end). The API
+// reports words as a flat list, so segments get them attached by time.
+func wordsWithin(words []domain.Word, start float64, end float64) []domain.Word {
+	var result []domain.Word
+	for _, word := range words {
+		if word.Start >= start && word.Start < end {
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+type retryCounterKey struct{}
+
+// WithRetryCounter returns a context under which Retry adds every retried
+// attempt to counter, so callers can report retries without parsing logs.
+func WithRetryCounter(ctx context.Context, counter *atomic.Int64) context.Context {
+	return context.WithValue(ctx, retryCounterKey{}, counter)
+}
+
 func Retry(ctx context.Context, logger zerolog.Logger, label string, fn func() error) error {
 	var err error
 	for attempt := 1; attempt <= 3; attempt++ {
@@ -88,6 +131,9 @@ func Retry(ctx context.Context, logger zerolog.Logger, label string, fn func() e
 		}
 
 		logger.Warn().Err(err).Int("attempt", attempt).Str("provider", label).Msg("transcription request failed, retrying")
+		if counter, ok := ctx.Value(retryCounterKey{}).(*atomic.Int64); ok {
+			counter.Add(1)
+		}
 
 		select {
 		case <-ctx.Done():
@@ -106,9 +152,7 @@ func shouldRetry(err error) bool {
 		return false
 	}
 
-	var withStatus statusCoder
-	if errors.As(err, &withStatus) {
-		code := withStatus.StatusCode()
+	if code, ok := statusCode(err); ok {
 		return code == 429 || code >= 500
 	}
 
@@ -131,6 +175,50 @@ func shouldRetry(err error) bool {
 	return false
 }
 
+// IsAuthError reports whether the provider rejected the API key or the key
+// lacks access to the endpoint or model.
+func IsAuthError(err error) bool {
+	if code, ok := statusCode(err); ok {
+		return code == 401 || code == 403
+	}
+	return containsAny(err, "401 unauthorized", "403 forbidden", "invalid_api_key", "incorrect api key")
+}
+
+// IsQuotaError reports whether the account ran out of quota or credit, or
+// stayed rate limited through every retry.
+func IsQuotaError(err error) bool {
+	if code, ok := statusCode(err); ok {
+		return code == 402 || code == 429
+	}
+	return containsAny(err, "insufficient_quota", "402 payment required", "429 too many requests")
+}
+
+// statusCode returns the HTTP status of a provider error, if it carries one.
+func statusCode(err error) (int, bool) {
+	var withStatus statusCoder
+	if errors.As(err, &withStatus) {
+		return withStatus.StatusCode(), true
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode != 0 {
+		return apiErr.StatusCode, true
+	}
+	return 0, false
+}
+
+func containsAny(err error, markers ...string) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range markers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 func MarshalRawArray(items [][]byte) ([]byte, error) {
 	if len(items) == 0 {
 		return nil, nil
@@ -155,3 +243,41 @@ func MarshalRawArray(items [][]byte) ([]byte, error) {
 
 	return json.MarshalIndent(rawItems, "", "  ")
 }
+
+// Translator is the part of an OpenAI-compatible client that serves
+// /audio/translations.
+type Translator interface {
+	Translate(ctx context.Context, params openai.AudioTranslationNewParams) (*openai.Translation, error)
+}
+
+// Translate sends the chunk to /audio/translations, which always produces
+// English text. It asks for verbose JSON when the model reports segment
+// timestamps so translated chunks keep their timings.
+func Translate(ctx context.Context, translator Translator, file io.Reader, req Request, caps domain.Capabilities) (string, []byte, error) {
+	params := openai.AudioTranslationNewParams{
+		File:           file,
+		Model:          req.Model,
+		ResponseFormat: openai.AudioTranslationNewParamsResponseFormatJSON,
+	}
+	if req.Prompt != "" && caps.SupportsPrompt {
+		params.Prompt = param.NewOpt(req.Prompt)
+	}
+	if caps.SupportsSegmentTimestamps {
+		params.ResponseFormat = openai.AudioTranslationNewParamsResponseFormatVerboseJSON
+	}
+
+	resp, err := translator.Translate(ctx, params)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.Text, []byte(resp.RawJSON()), nil
+}
+
+// TimestampGranularities returns the granularities to request for a
+// verbose JSON transcription.
+func TimestampGranularities(req Request, caps domain.Capabilities) []string {
+	if req.WantWords && caps.SupportsWordTimestamps {
+		return []string{"word", "segment"}
+	}
+	return []string{"segment"}
+}
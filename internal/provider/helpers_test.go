@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/arykalin/whisper-cli/internal/domain"
+	"github.com/openai/openai-go"
+	"github.com/rs/zerolog"
+)
+
+type statusError int
+
+func (s statusError) Error() string {
+	return fmt.Sprintf("status %d", int(s))
+}
+
+func (s statusError) StatusCode() int {
+	return int(s)
+}
+
+func TestClassifiesAuthAndQuotaErrors(t *testing.T) {
+	t.Parallel()
+
+	if !IsAuthError(fmt.Errorf("chunk 0: %w", statusError(401))) || !IsAuthError(&openai.Error{StatusCode: 403}) {
+		t.Fatal("expected 401 and 403 to be auth errors")
+	}
+	if !IsQuotaError(statusError(429)) || !IsQuotaError(errors.New(`POST "x": 429 Too Many Requests {"code":"insufficient_quota"}`)) {
+		t.Fatal("expected 429 to be a quota error")
+	}
+	if IsAuthError(statusError(500)) || IsQuotaError(statusError(500)) || IsAuthError(nil) {
+		t.Fatal("server errors must not be classified as auth or quota")
+	}
+}
+
+func TestRetryCountsRetriedAttempts(t *testing.T) {
+	t.Parallel()
+
+	var counter atomic.Int64
+	ctx := WithRetryCounter(context.Background(), &counter)
+	calls := 0
+	err := Retry(ctx, zerolog.New(io.Discard), "test", func() error {
+		calls++
+		if calls < 3 {
+			return statusError(503)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry returned error: %v", err)
+	}
+	if counter.Load() != 2 {
+		t.Fatalf("retries = %d, want 2", counter.Load())
+	}
+}
+
+type translatorFunc func(params openai.AudioTranslationNewParams) (*openai.Translation, error)
+
+func (f translatorFunc) Translate(_ context.Context, params openai.AudioTranslationNewParams) (*openai.Translation, error) {
+	return f(params)
+}
+
+func TestTranslateAsksForVerboseJSONOnlyWithSegmentTimestamps(t *testing.T) {
+	t.Parallel()
+
+	var formats []openai.AudioTranslationNewParamsResponseFormat
+	translator := translatorFunc(func(params openai.AudioTranslationNewParams) (*openai.Translation, error) {
+		formats = append(formats, params.ResponseFormat)
+		return &openai.Translation{Text: "hello"}, nil
+	})
+	req := Request{Model: "whisper-1"}
+
+	text, _, err := Translate(context.Background(), translator, nil, req, domain.Capabilities{SupportsSegmentTimestamps: true})
+	if err != nil || text != "hello" {
+		t.Fatalf("unexpected translation %q: %v", text, err)
+	}
+	if _, _, err := Translate(context.Background(), translator, nil, req, domain.Capabilities{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(formats) != 2 || formats[0] != openai.AudioTranslationNewParamsResponseFormatVerboseJSON || formats[1] != openai.AudioTranslationNewParamsResponseFormatJSON {
+		t.Fatalf("unexpected response formats %v", formats)
+	}
+}
+
+func TestTimestampGranularitiesIncludeWordsOnlyWhenSupported(t *testing.T) {
+	t.Parallel()
+
+	got := TimestampGranularities(Request{WantWords: true}, domain.Capabilities{SupportsWordTimestamps: true})
+	if len(got) != 2 || got[0] != "word" {
+		t.Fatalf("expected word and segment granularities, got %v", got)
+	}
+	got = TimestampGranularities(Request{WantWords: true}, domain.Capabilities{})
+	if len(got) != 1 || got[0] != "segment" {
+		t.Fatalf("expected segment granularity only, got %v", got)
+	}
+}
@@ -20,16 +20,22 @@ const diarizeModel = "gpt-4o-transcribe-diarize"
 
 type requester interface {
 	Transcribe(ctx context.Context, params openai.AudioTranscriptionNewParams) (*openai.Transcription, error)
+	Translate(ctx context.Context, params openai.AudioTranslationNewParams) (*openai.Translation, error)
 }
 
 type serviceRequester struct {
-	service openai.AudioTranscriptionService
+	service      openai.AudioTranscriptionService
+	translations openai.AudioTranslationService
 }
 
 func (s serviceRequester) Transcribe(ctx context.Context, params openai.AudioTranscriptionNewParams) (*openai.Transcription, error) {
 	return s.service.New(ctx, params)
 }
 
+func (s serviceRequester) Translate(ctx context.Context, params openai.AudioTranslationNewParams) (*openai.Translation, error) {
+	return s.translations.New(ctx, params)
+}
+
 type Provider struct {
 	apiKey    string
 	fs        fsx.FS
@@ -47,7 +53,7 @@ func New(apiKey string, fs fsx.FS, logger zerolog.Logger) *Provider {
 	return &Provider{
 		apiKey:    apiKey,
 		fs:        fs,
-		requester: serviceRequester{service: client.Audio.Transcriptions},
+		requester: serviceRequester{service: client.Audio.Transcriptions, translations: client.Audio.Translations},
 		logger:    logger.With().Str("provider", string(domain.ProviderOpenAI)).Logger(),
 	}
 }
@@ -108,6 +114,11 @@ func (p *Provider) Transcribe(ctx context.Context, req provider.Request) (provid
 			}
 		}()
 
+		if req.Task == domain.TaskTranslate {
+			text, raw, err = provider.Translate(ctx, p.requester, file, req, caps)
+			return err
+		}
+
 		params := openai.AudioTranscriptionNewParams{
 			File:     file,
 			Model:    req.Model,
@@ -124,7 +135,7 @@ func (p *Provider) Transcribe(ctx context.Context, req provider.Request) (provid
 			}
 		} else if caps.SupportsSegmentTimestamps {
 			params.ResponseFormat = openai.AudioResponseFormatVerboseJSON
-			params.TimestampGranularities = []string{"segment"}
+			params.TimestampGranularities = provider.TimestampGranularities(req, caps)
 		} else {
 			params.ResponseFormat = openai.AudioResponseFormatJSON
 		}
@@ -149,20 +160,40 @@ func (p *Provider) Transcribe(ctx context.Context, req provider.Request) (provid
 	}, nil
 }
 
+// maxUploadBytes is the OpenAI audio endpoint file size limit.
+const maxUploadBytes = 25 << 20
+
+// inputFormats are the upload formats the OpenAI audio endpoint accepts.
+var inputFormats = []string{"flac", "m4a", "mp3", "mp4", "mpeg", "mpga", "oga", "ogg", "wav", "webm"}
+
 var capabilities = map[string]domain.Capabilities{
 	openai.AudioModelWhisper1: {
 		SupportsPrompt:            true,
 		SupportsSegmentTimestamps: true,
+		SupportsWordTimestamps:    true,
 		SupportsSRT:               true,
 		SupportsVTT:               true,
+		SupportsTranslation:       true,
+		MaxUploadBytes:            maxUploadBytes,
+		InputFormats:              inputFormats,
+		AudioProfile:              "speech-aac",
 	},
 	openai.AudioModelGPT4oTranscribe: {
 		SupportsPrompt: true,
+		MaxUploadBytes: maxUploadBytes,
+		InputFormats:   inputFormats,
+		AudioProfile:   "speech-aac",
 	},
 	openai.AudioModelGPT4oMiniTranscribe: {
 		SupportsPrompt: true,
+		MaxUploadBytes: maxUploadBytes,
+		InputFormats:   inputFormats,
+		AudioProfile:   "speech-aac",
 	},
 	diarizeModel: {
 		SupportsDiarization: true,
+		MaxUploadBytes:      maxUploadBytes,
+		InputFormats:        inputFormats,
+		AudioProfile:        "speech-aac",
 	},
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/arykalin/whisper-cli/internal/domain"
 	"github.com/arykalin/whisper-cli/internal/platform/fsx"
 	"github.com/arykalin/whisper-cli/internal/provider"
 	"github.com/openai/openai-go"
@@ -16,14 +17,16 @@ import (
 )
 
 type fakeRequester struct {
-	steps      []fakeStep
-	callCount  int
-	lastParams []openai.AudioTranscriptionNewParams
+	steps                 []fakeStep
+	callCount             int
+	lastParams            []openai.AudioTranscriptionNewParams
+	lastTranslationParams []openai.AudioTranslationNewParams
 }
 
 type fakeStep struct {
-	response *openai.Transcription
-	err      error
+	response    *openai.Transcription
+	translation *openai.Translation
+	err         error
 }
 
 func (f *fakeRequester) Transcribe(ctx context.Context, params openai.AudioTranscriptionNewParams) (*openai.Transcription, error) {
@@ -34,6 +37,14 @@ func (f *fakeRequester) Transcribe(ctx context.Context, params openai.AudioTrans
 	return step.response, step.err
 }
 
+func (f *fakeRequester) Translate(ctx context.Context, params openai.AudioTranslationNewParams) (*openai.Translation, error) {
+	f.callCount++
+	f.lastTranslationParams = append(f.lastTranslationParams, params)
+	step := f.steps[0]
+	f.steps = f.steps[1:]
+	return step.translation, step.err
+}
+
 type statusError struct {
 	code int
 	msg  string
@@ -133,6 +144,49 @@ func mustTranscription(raw string) *openai.Transcription {
 	return &transcription
 }
 
+func TestProviderUsesTranslationEndpoint(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	audioPath := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(audioPath, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write audio: %v", err)
+	}
+
+	var translation openai.Translation
+	if err := json.Unmarshal([]byte(`{"text":"hello","segments":[{"start":0,"end":1,"text":"hello"}]}`), &translation); err != nil {
+		t.Fatalf("unmarshal translation: %v", err)
+	}
+	requester := &fakeRequester{
+		steps: []fakeStep{{translation: &translation}},
+	}
+	providerClient := newWithRequester("test-key", fsx.OS{}, zerolog.New(io.Discard), requester)
+
+	response, err := providerClient.Transcribe(context.Background(), provider.Request{
+		FilePath: audioPath,
+		Task:     domain.TaskTranslate,
+		Model:    openai.AudioModelWhisper1,
+		Language: "ru",
+		Prompt:   "glossary",
+	})
+	if err != nil {
+		t.Fatalf("Transcribe returned error: %v", err)
+	}
+	if len(requester.lastParams) != 0 || len(requester.lastTranslationParams) != 1 {
+		t.Fatalf("transcription calls = %d, translation calls = %d", len(requester.lastParams), len(requester.lastTranslationParams))
+	}
+	params := requester.lastTranslationParams[0]
+	if params.ResponseFormat != openai.AudioTranslationNewParamsResponseFormatVerboseJSON {
+		t.Fatalf("responseFormat = %s", params.ResponseFormat)
+	}
+	if params.Prompt.Value != "glossary" {
+		t.Fatalf("prompt = %q", params.Prompt.Value)
+	}
+	if response.Transcript.Text != "hello" || len(response.Transcript.Segments) != 1 {
+		t.Fatalf("transcript = %#v", response.Transcript)
+	}
+}
+
 func TestProviderPreflightRequiresKey(t *testing.T) {
 	t.Parallel()
 
@@ -157,3 +211,41 @@ func TestProviderSupportedModelsAreSorted(t *testing.T) {
 		t.Fatalf("supported models = %v, want %v", models, want)
 	}
 }
+
+func TestProviderRequestsWordTimestamps(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	audioPath := filepath.Join(dir, "input.m4a")
+	if err := os.WriteFile(audioPath, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write audio: %v", err)
+	}
+
+	requester := &fakeRequester{
+		steps: []fakeStep{
+			{response: mustTranscription(`{"text":"hello world","segments":[{"start":0,"end":1,"text":"hello"},{"start":1,"end":2,"text":"world"}],"words":[{"word":"hello","start":0.1,"end":0.5},{"word":"world","start":1.2,"end":1.6}]}`)},
+		},
+	}
+	providerClient := newWithRequester("test-key", fsx.OS{}, zerolog.New(io.Discard), requester)
+
+	response, err := providerClient.Transcribe(context.Background(), provider.Request{
+		FilePath:  audioPath,
+		Model:     openai.AudioModelWhisper1,
+		WantWords: true,
+	})
+	if err != nil {
+		t.Fatalf("Transcribe returned error: %v", err)
+	}
+
+	params := requester.lastParams[0]
+	if strings.Join(params.TimestampGranularities, ",") != "word,segment" {
+		t.Fatalf("timestamp granularities = %v", params.TimestampGranularities)
+	}
+	if len(response.Transcript.Words) != 2 {
+		t.Fatalf("words = %v", response.Transcript.Words)
+	}
+	segments := response.Transcript.Segments
+	if len(segments) != 2 || len(segments[0].Words) != 1 || segments[1].Words[0].Word != "world" {
+		t.Fatalf("segment words = %#v", segments)
+	}
+}
@@ -11,10 +11,12 @@ import (
 
 type Request struct {
 	FilePath        string
+	Task            domain.Task
 	Model           string
 	Language        string
 	Prompt          string
 	WantDiarization bool
+	WantWords       bool
 	WantRaw         bool
 }
 